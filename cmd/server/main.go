@@ -1,12 +1,21 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 
+	"github.com/JMKobayashi/Rate-Limiter-GO/internal/entity"
 	"github.com/JMKobayashi/Rate-Limiter-GO/internal/limiter/strategy"
 	"github.com/JMKobayashi/Rate-Limiter-GO/internal/middleware"
+	"github.com/JMKobayashi/Rate-Limiter-GO/internal/repository"
 	"github.com/JMKobayashi/Rate-Limiter-GO/internal/usecase"
 	"github.com/JMKobayashi/Rate-Limiter-GO/pkg/config"
 	"github.com/gin-gonic/gin"
@@ -28,14 +37,26 @@ func main() {
 		DB:       int(cfg.RedisDB),
 	})
 
-	// Testa conexão com Redis
+	// Define o tratamento das requisições quando o Redis falha
+	failurePolicy, err := middleware.ParseFailurePolicy(cfg.FailurePolicy)
+	if err != nil {
+		log.Fatalf("Erro na configuração FAILURE_POLICY: %v", err)
+	}
+	failureRetryAfter := time.Duration(cfg.FailureRetryAfter) * time.Second
+
+	// Testa conexão com Redis; com fail-open ou fallback, o servidor sobe
+	// mesmo sem o Redis
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	if err := redisClient.Ping(ctx).Err(); err != nil {
-		log.Fatalf("Erro ao conectar com Redis: %v", err)
+		if failurePolicy == middleware.FailClosed {
+			log.Fatalf("Erro ao conectar com Redis: %v", err)
+		}
+		log.Printf("Erro ao conectar com Redis, usando a política de falha %s: %v", failurePolicy, err)
+	} else {
+		log.Println("Conexão com Redis estabelecida com sucesso")
 	}
-	log.Println("Conexão com Redis estabelecida com sucesso")
 
 	// Configura a estratégia Redis
 	redisConfig := map[string]interface{}{
@@ -49,6 +70,144 @@ func main() {
 	}
 	log.Println("Estratégia Redis inicializada com sucesso")
 
+	// Valida os limites globais; um limite zero não permitiria nenhuma requisição
+	if cfg.RateLimitIP < 1 {
+		log.Fatalf("Erro na configuração RATE_LIMIT_IP: limite inválido: %d", cfg.RateLimitIP)
+	}
+	if cfg.RateLimitToken < 1 {
+		log.Fatalf("Erro na configuração RATE_LIMIT_TOKEN: limite inválido: %d", cfg.RateLimitToken)
+	}
+
+	// Valida os algoritmos de limitação configurados
+	algorithmIP, err := strategy.ParseAlgorithm(cfg.AlgorithmIP)
+	if err != nil {
+		log.Fatalf("Erro na configuração RATE_LIMIT_ALGORITHM_IP: %v", err)
+	}
+	algorithmToken, err := strategy.ParseAlgorithm(cfg.AlgorithmToken)
+	if err != nil {
+		log.Fatalf("Erro na configuração RATE_LIMIT_ALGORITHM_TOKEN: %v", err)
+	}
+
+	// Carrega o fuso horário usado para alinhar as cotas ao calendário
+	quotaLocation, err := time.LoadLocation(cfg.QuotaTimezone)
+	if err != nil {
+		log.Fatalf("Erro na configuração QUOTA_TIMEZONE: %v", err)
+	}
+
+	// Valida o agrupamento de IPs por rede
+	if cfg.IPPrefixV4 < 1 || cfg.IPPrefixV4 > 32 {
+		log.Fatalf("Erro na configuração RATE_LIMIT_IPV4_PREFIX: prefixo inválido: %d", cfg.IPPrefixV4)
+	}
+	if cfg.IPPrefixV6 < 1 || cfg.IPPrefixV6 > 128 {
+		log.Fatalf("Erro na configuração RATE_LIMIT_IPV6_PREFIX: prefixo inválido: %d", cfg.IPPrefixV6)
+	}
+
+	// Grava os tokens no armazenamento como HMAC-SHA256, em vez do token em claro
+	var tokenHasher *entity.TokenHasher
+	if cfg.TokenHashSecretFile != "" {
+		tokenHasher, err = loadTokenHasher(cfg.TokenHashSecretFile)
+		if err != nil {
+			log.Fatalf("Erro na configuração TOKEN_HASH_SECRET_FILE: %v", err)
+		}
+		log.Println("Hash de tokens habilitado")
+
+		// Renomeia as chaves gravadas com o token em claro
+		if cfg.TokenHashMigrate {
+			redisRepository, ok := redisStrategy.(*strategy.RedisRateLimiterRepository)
+			if !ok {
+				log.Fatal("Estratégia Redis não suporta a migração das chaves de tokens")
+			}
+			migrated, err := redisRepository.MigrateTokenKeys(context.Background(), tokenHasher)
+			if err != nil {
+				log.Fatalf("Erro ao migrar as chaves de tokens: %v", err)
+			}
+			log.Printf("Chaves de tokens migradas para o hash: %d", migrated)
+		}
+	} else if cfg.TokenHashMigrate {
+		log.Fatal("TOKEN_HASH_MIGRATE requer TOKEN_HASH_SECRET_FILE")
+	}
+
+	// Durante uma indisponibilidade do Redis, o circuit breaker aplica a
+	// política de falha sem esperar o tempo limite de cada operação
+	var circuitBreaker *strategy.CircuitBreakerRepository
+	if cfg.BreakerEnabled {
+		if cfg.BreakerFailureRate < 1 || cfg.BreakerFailureRate > 100 {
+			log.Fatalf("Erro na configuração CIRCUIT_BREAKER_FAILURE_RATE: taxa inválida: %d", cfg.BreakerFailureRate)
+		}
+		circuitBreaker, err = strategy.NewCircuitBreakerRepository(redisStrategy,
+			strategy.WithCircuitFailureThreshold(float64(cfg.BreakerFailureRate)/100, cfg.BreakerMinRequests),
+			strategy.WithCircuitWindow(time.Duration(cfg.BreakerWindow)*time.Second),
+			strategy.WithCircuitOpenTimeout(time.Duration(cfg.BreakerOpenTimeout)*time.Second),
+			strategy.WithCircuitProbes(cfg.BreakerProbes),
+			strategy.WithCircuitStateChange(func(from, to strategy.CircuitState) {
+				log.Printf("Circuit breaker do Redis: %s -> %s", from, to)
+			}),
+		)
+		if err != nil {
+			log.Fatalf("Erro ao inicializar o circuit breaker: %v", err)
+		}
+		redisStrategy = circuitBreaker
+		log.Printf("Circuit breaker habilitado: falhas=%d%%, mínimo=%d, janela=%ds, aberto=%ds, testes=%d",
+			cfg.BreakerFailureRate, cfg.BreakerMinRequests, cfg.BreakerWindow, cfg.BreakerOpenTimeout, cfg.BreakerProbes)
+	}
+
+	// Monta o registro de tokens: primeiro o arquivo, depois o Redis
+	var tokenRegistries []repository.TokenRegistry
+	if cfg.TokenRegistryFile != "" {
+		fileRegistry, err := strategy.LoadTokenRegistryFile(cfg.TokenRegistryFile)
+		if err != nil {
+			log.Fatalf("Erro na configuração TOKEN_REGISTRY_FILE: %v", err)
+		}
+		tokenRegistries = append(tokenRegistries, fileRegistry)
+		log.Printf("Registro de tokens carregado de %s", cfg.TokenRegistryFile)
+	}
+	if cfg.TokenRegistryRedis {
+		var redisRegistry repository.TokenRegistry = strategy.NewRedisTokenRegistry(redisClient,
+			strategy.WithRegistryTokenHasher(tokenHasher))
+		// As consultas ao registro passam pelo mesmo circuito do Redis
+		if circuitBreaker != nil {
+			redisRegistry = circuitBreaker.TokenRegistry(redisRegistry)
+		}
+		tokenRegistries = append(tokenRegistries, redisRegistry)
+		log.Println("Registro de tokens no Redis habilitado")
+	}
+	var tokenRegistry repository.TokenRegistry
+	if len(tokenRegistries) > 0 {
+		tokenRegistry = strategy.NewChainTokenRegistry(tokenRegistries...)
+	}
+
+	// Identifica os clientes pelo JWT do header Authorization
+	var jwtIdentity *middleware.JWTIdentity
+	limitRegistry := tokenRegistry
+	if cfg.JWTSecretFile != "" || cfg.JWTPublicKeyFile != "" || cfg.JWTJWKSFile != "" {
+		keySet, err := loadJWTKeySet(cfg)
+		if err != nil {
+			log.Fatalf("Erro na configuração do JWT: %v", err)
+		}
+		var jwtOpts []middleware.JWTOption
+		if cfg.JWTLimitClaim != "" {
+			jwtOpts = append(jwtOpts, middleware.WithJWTLimitClaim(cfg.JWTLimitClaim))
+
+			// O limite do claim tem precedência sobre os registros de tokens
+			limitRegistry = strategy.NewChainTokenRegistry(
+				append([]repository.TokenRegistry{usecase.ContextTokenRegistry()}, tokenRegistries...)...)
+		}
+		jwtIdentity = middleware.NewJWTIdentity(keySet, cfg.JWTIdentityClaim, jwtOpts...)
+		log.Printf("Identidade por JWT habilitada: claim=%s, claim de limite=%s", cfg.JWTIdentityClaim, cfg.JWTLimitClaim)
+	}
+
+	// No modo fallback, a memória local assume enquanto o Redis está com falhas
+	var fallbackRepository *strategy.FallbackRepository
+	if failurePolicy == middleware.FailFallback {
+		fallbackRepository, err = strategy.NewFallbackRepository(redisStrategy, failureRetryAfter)
+		if err != nil {
+			log.Fatalf("Erro na configuração FAILURE_POLICY: %v", err)
+		}
+		redisStrategy = fallbackRepository
+	}
+	log.Printf("Política de falha do armazenamento: %s, Retry-After=%ds, tempo limite=%dms",
+		failurePolicy, cfg.FailureRetryAfter, cfg.StorageTimeout)
+
 	// Inicializa o caso de uso
 	rateLimiterUseCase := usecase.NewRateLimiterUseCase(
 		redisStrategy,
@@ -58,15 +217,200 @@ func main() {
 		cfg.BlockDurationToken,
 		cfg.EnableIPLimiter,
 		cfg.EnableTokenLimiter,
+		usecase.WithWindows(
+			time.Duration(cfg.WindowIP)*time.Second,
+			time.Duration(cfg.WindowToken)*time.Second,
+		),
+		usecase.WithAlgorithms(algorithmIP, algorithmToken),
+		usecase.WithBurst(cfg.BurstIP, cfg.BurstToken),
+		usecase.WithIPPrefix(cfg.IPPrefixV4, cfg.IPPrefixV6),
+		usecase.WithQuota(int64(cfg.QuotaDailyToken), usecase.DailyQuota),
+		usecase.WithQuota(int64(cfg.QuotaMonthlyToken), usecase.MonthlyQuota),
+		usecase.WithQuotaLocation(quotaLocation),
+		usecase.WithTokenRegistry(limitRegistry),
+		usecase.WithTokenHasher(tokenHasher),
 	)
-	log.Printf("Rate Limiter configurado: IP=%d, Token=%d, BlockIP=%d, BlockToken=%d",
-		cfg.RateLimitIP, cfg.RateLimitToken, cfg.BlockDurationIP, cfg.BlockDurationToken)
+	log.Printf("Rate Limiter configurado: IP=%d/%ds (%s), Token=%d/%ds (%s), BlockIP=%d, BlockToken=%d",
+		cfg.RateLimitIP, cfg.WindowIP, algorithmIP, cfg.RateLimitToken, cfg.WindowToken, algorithmToken,
+		cfg.BlockDurationIP, cfg.BlockDurationToken)
+	log.Printf("IPs agrupados por rede: IPv4=/%d, IPv6=/%d", cfg.IPPrefixV4, cfg.IPPrefixV6)
+	if cfg.QuotaDailyToken > 0 || cfg.QuotaMonthlyToken > 0 {
+		log.Printf("Cotas por token: diária=%d, mensal=%d (%s)",
+			cfg.QuotaDailyToken, cfg.QuotaMonthlyToken, quotaLocation)
+	}
 
 	// Configura o servidor Gin
 	r := gin.Default()
 
+	// Só confia nos headers de proxy quando a conexão vem de TRUSTED_PROXIES;
+	// sem proxies configurados, o IP da conexão é sempre usado
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Fatalf("Erro na configuração TRUSTED_PROXIES: %v", err)
+	}
+	clientIPResolver, err := middleware.NewClientIPResolver(cfg.TrustedProxies, cfg.ClientIPHeaders)
+	if err != nil {
+		log.Fatalf("Erro na configuração TRUSTED_PROXIES: %v", err)
+	}
+	var middlewareOpts []middleware.Option
+
+	// Monta as listas de redes permitidas e bloqueadas
+	if len(cfg.IPAllowlist) > 0 || len(cfg.IPDenylist) > 0 || cfg.IPAccessListFile != "" {
+		ipAccessList, err := loadIPAccessList(cfg)
+		if err != nil {
+			log.Fatalf("Erro na configuração das listas de acesso por IP: %v", err)
+		}
+		middlewareOpts = append(middlewareOpts, middleware.WithIPAccessList(ipAccessList))
+		log.Printf("Listas de acesso por IP habilitadas: permitidas=%v, bloqueadas=%v, arquivo=%s",
+			cfg.IPAllowlist, cfg.IPDenylist, cfg.IPAccessListFile)
+
+		// Recarrega as listas ao receber SIGHUP
+		go reloadIPAccessListOnSignal(cfg, ipAccessList)
+	}
+
+	// Define os headers de rate limit enviados nas respostas
+	headerStyle, err := middleware.ParseHeaderStyle(cfg.HeaderStyle)
+	if err != nil {
+		log.Fatalf("Erro na configuração RATE_LIMIT_HEADERS: %v", err)
+	}
+	middlewareOpts = append(middlewareOpts,
+		middleware.WithHeaderStyle(headerStyle),
+		middleware.WithClientIPResolver(clientIPResolver),
+		middleware.WithFailurePolicy(failurePolicy, failureRetryAfter),
+		middleware.WithStorageTimeout(time.Duration(cfg.StorageTimeout)*time.Millisecond),
+	)
+
+	if jwtIdentity != nil {
+		middlewareOpts = append(middlewareOpts, middleware.WithIdentityExtractor(jwtIdentity))
+	}
+
+	// Define onde a API key é procurada, em ordem de prioridade
+	credentialSources, err := middleware.ParseCredentialSources(cfg.TokenSources)
+	if err != nil {
+		log.Fatalf("Erro na configuração TOKEN_SOURCES: %v", err)
+	}
+	for _, source := range strings.Split(cfg.TokenSources, ",") {
+		if jwtIdentity != nil && strings.EqualFold(strings.TrimSpace(source), "bearer") {
+			log.Fatal("TOKEN_SOURCES não pode usar bearer junto com a identidade por JWT")
+		}
+	}
+	middlewareOpts = append(middlewareOpts, middleware.WithCredentialSources(credentialSources...))
+	log.Printf("Fontes de API key: %s", cfg.TokenSources)
+
+	// Define o tratamento de API keys que não estão no registro de tokens
+	unknownKeyPolicy, err := middleware.ParseUnknownKeyPolicy(cfg.UnknownKeyPolicy)
+	if err != nil {
+		log.Fatalf("Erro na configuração UNKNOWN_KEY_POLICY: %v", err)
+	}
+	if unknownKeyPolicy != middleware.UnknownKeyAllow {
+		if tokenRegistry == nil {
+			log.Fatal("UNKNOWN_KEY_POLICY requer um registro de tokens (TOKEN_REGISTRY_FILE ou TOKEN_REGISTRY_REDIS)")
+		}
+		middlewareOpts = append(middlewareOpts, middleware.WithKeyValidator(
+			middleware.RegistryKeyValidator(tokenRegistry),
+			unknownKeyPolicy,
+		))
+		log.Printf("API keys desconhecidas: %s", unknownKeyPolicy)
+	}
+
+	// Habilita o modo leaky bucket, que atrasa as requisições em vez de rejeitá-las
+	if cfg.LeakyBucketEnabled {
+		if cfg.LeakyBucketRate <= 0 {
+			log.Fatalf("Erro na configuração LEAKY_BUCKET_RATE: deve ser maior que zero")
+		}
+		middlewareOpts = append(middlewareOpts, middleware.WithLeakyBucket(middleware.NewLeakyBucket(
+			float64(cfg.LeakyBucketRate),
+			cfg.LeakyBucketQueue,
+			time.Duration(cfg.LeakyBucketMaxWait)*time.Millisecond,
+		)))
+		log.Printf("Leaky bucket habilitado: %d req/s, fila=%d, espera máxima=%dms",
+			cfg.LeakyBucketRate, cfg.LeakyBucketQueue, cfg.LeakyBucketMaxWait)
+	}
+
+	// Limita as requisições simultâneas por IP e por token
+	if cfg.ConcurrencyIP > 0 || cfg.ConcurrencyToken > 0 {
+		concurrencyRepository, ok := redisStrategy.(strategy.ConcurrencyRepository)
+		if !ok {
+			log.Fatal("Estratégia Redis não suporta limitação de requisições simultâneas")
+		}
+		concurrencyLimiter := usecase.NewConcurrencyLimiterUseCase(
+			concurrencyRepository,
+			cfg.ConcurrencyIP,
+			cfg.ConcurrencyToken,
+			time.Duration(cfg.ConcurrencyLease)*time.Second,
+			usecase.WithConcurrencyTokenHasher(tokenHasher),
+		)
+		middlewareOpts = append(middlewareOpts, middleware.WithConcurrencyLimiter(concurrencyLimiter))
+		log.Printf("Limite de requisições simultâneas: IP=%d, Token=%d, Lease=%ds",
+			cfg.ConcurrencyIP, cfg.ConcurrencyToken, cfg.ConcurrencyLease)
+	}
+
+	// Define o custo das rotas mais caras
+	if cfg.RouteCosts != "" {
+		routeCosts, err := middleware.ParseRouteCosts(cfg.RouteCosts)
+		if err != nil {
+			log.Fatalf("Erro na configuração RATE_LIMIT_ROUTE_COSTS: %v", err)
+		}
+		middlewareOpts = append(middlewareOpts, middleware.WithRouteCosts(routeCosts))
+		log.Printf("Custos por rota: %v", routeCosts)
+	}
+
+	// Define as dimensões que compõem o identificador limitado
+	if cfg.KeyDimensions != "" {
+		keyFunc, err := middleware.ParseKeyFunc(cfg.KeyDimensions)
+		if err != nil {
+			log.Fatalf("Erro na configuração RATE_LIMIT_KEY: %v", err)
+		}
+		middlewareOpts = append(middlewareOpts, middleware.WithKeyFunc(keyFunc))
+		log.Printf("Identificador limitado: %s", cfg.KeyDimensions)
+	}
+
+	// Define os limites próprios de algumas rotas; as demais usam o limite global
+	if cfg.RouteRules != "" {
+		specs, err := middleware.ParseRuleSpecs(cfg.RouteRules)
+		if err != nil {
+			log.Fatalf("Erro na configuração RATE_LIMIT_RULES: %v", err)
+		}
+		rules := make([]middleware.RouteRule, 0, len(specs))
+		for _, spec := range specs {
+			rules = append(rules, middleware.RouteRule{
+				Route: spec.Route,
+				Scope: spec.Scope,
+				UseCase: usecase.NewRateLimiterUseCase(
+					redisStrategy,
+					spec.Limit,
+					spec.Limit,
+					cfg.BlockDurationIP,
+					cfg.BlockDurationToken,
+					cfg.EnableIPLimiter,
+					cfg.EnableTokenLimiter,
+					usecase.WithWindows(spec.Window, spec.Window),
+					usecase.WithAlgorithms(algorithmIP, algorithmToken),
+					usecase.WithIPPrefix(cfg.IPPrefixV4, cfg.IPPrefixV6),
+					usecase.WithKeyNamespace(spec.Namespace()),
+					usecase.WithTokenHasher(tokenHasher),
+					// As cotas por token também valem para as rotas com regra própria
+					usecase.WithQuota(int64(cfg.QuotaDailyToken), usecase.DailyQuota),
+					usecase.WithQuota(int64(cfg.QuotaMonthlyToken), usecase.MonthlyQuota),
+					usecase.WithQuotaLocation(quotaLocation),
+				),
+			})
+			log.Printf("Regra de rota: %s = %d/%s por %s", spec.Route, spec.Limit, spec.Window, spec.Scope)
+		}
+		routeRules, err := middleware.NewRouteRules(rules...)
+		if err != nil {
+			log.Fatalf("Erro na configuração RATE_LIMIT_RULES: %v", err)
+		}
+		middlewareOpts = append(middlewareOpts, middleware.WithRouteRules(routeRules))
+	}
+
+	// Health check com o estado do armazenamento; registrado antes do
+	// middleware para não ser limitado
+	r.GET("/health", func(c *gin.Context) {
+		c.JSON(200, storageHealth(failurePolicy, circuitBreaker, fallbackRepository))
+	})
+
 	// Adiciona o middleware de rate limiting
-	r.Use(middleware.RateLimiter(rateLimiterUseCase))
+	r.Use(middleware.RateLimiter(rateLimiterUseCase, middlewareOpts...))
 	log.Println("Middleware de Rate Limiting adicionado")
 
 	// Rota de exemplo
@@ -82,3 +426,93 @@ func main() {
 		log.Fatalf("Erro ao iniciar o servidor: %v", err)
 	}
 }
+
+// ipAccessLists combina as listas de IP_ALLOWLIST e IP_DENYLIST com as do
+// arquivo IP_ACCESS_LIST_FILE
+func ipAccessLists(cfg *config.Config) (allow, deny []string, err error) {
+	allow = append(allow, cfg.IPAllowlist...)
+	deny = append(deny, cfg.IPDenylist...)
+	if cfg.IPAccessListFile != "" {
+		file, err := middleware.LoadIPAccessListFile(cfg.IPAccessListFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		allow = append(allow, file.Allow...)
+		deny = append(deny, file.Deny...)
+	}
+	return allow, deny, nil
+}
+
+// loadIPAccessList cria as listas de acesso por IP a partir da configuração
+func loadIPAccessList(cfg *config.Config) (*middleware.IPAccessList, error) {
+	allow, deny, err := ipAccessLists(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return middleware.NewIPAccessList(allow, deny)
+}
+
+// reloadIPAccessListOnSignal relê o arquivo das listas de acesso a cada SIGHUP.
+// Em caso de erro, as listas atuais são mantidas.
+func reloadIPAccessListOnSignal(cfg *config.Config, list *middleware.IPAccessList) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	for range signals {
+		allow, deny, err := ipAccessLists(cfg)
+		if err == nil {
+			err = list.Update(allow, deny)
+		}
+		if err != nil {
+			log.Printf("Erro ao recarregar as listas de acesso por IP: %v", err)
+			continue
+		}
+		log.Printf("Listas de acesso por IP recarregadas: %d permitidas, %d bloqueadas", len(allow), len(deny))
+	}
+}
+
+// loadJWTKeySet carrega as chaves de verificação do JWT da única fonte configurada
+func loadJWTKeySet(cfg *config.Config) (*middleware.JWTKeySet, error) {
+	switch {
+	case cfg.JWTSecretFile != "" && cfg.JWTPublicKeyFile == "" && cfg.JWTJWKSFile == "":
+		return middleware.LoadHMACSecretFile(cfg.JWTSecretFile)
+	case cfg.JWTPublicKeyFile != "" && cfg.JWTSecretFile == "" && cfg.JWTJWKSFile == "":
+		return middleware.LoadRSAPublicKeyFile(cfg.JWTPublicKeyFile)
+	case cfg.JWTJWKSFile != "" && cfg.JWTSecretFile == "" && cfg.JWTPublicKeyFile == "":
+		return middleware.LoadJWKSFile(cfg.JWTJWKSFile)
+	default:
+		return nil, errors.New("configure apenas uma entre JWT_HS256_SECRET_FILE, JWT_RS256_PUBLIC_KEY_FILE e JWT_JWKS_FILE")
+	}
+}
+
+// loadTokenHasher cria o hasher de tokens com o segredo lido do arquivo
+func loadTokenHasher(path string) (*entity.TokenHasher, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler segredo do hash de tokens: %w", err)
+	}
+	return entity.NewTokenHasher(bytes.TrimSpace(data))
+}
+
+// storageHealth descreve o estado do armazenamento do rate limiter. O status
+// é degraded enquanto o circuit breaker não está fechado ou o fallback em
+// memória está em uso.
+func storageHealth(policy middleware.FailurePolicy, breaker *strategy.CircuitBreakerRepository, fallback *strategy.FallbackRepository) gin.H {
+	status := "ok"
+	storage := gin.H{"failure_policy": policy}
+
+	if breaker != nil {
+		stats := breaker.Stats()
+		if stats.State != strategy.CircuitClosed {
+			status = "degraded"
+		}
+		storage["circuit_breaker"] = stats
+	}
+	if fallback != nil {
+		storage["fallback_active"] = !fallback.Healthy()
+		if !fallback.Healthy() {
+			status = "degraded"
+		}
+	}
+
+	return gin.H{"status": status, "storage": storage}
+}
@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"fmt"
+	"net"
+)
+
+const (
+	// FullPrefixIPv4 não agrega endereços IPv4
+	FullPrefixIPv4 = 32
+	// FullPrefixIPv6 não agrega endereços IPv6
+	FullPrefixIPv6 = 128
+)
+
+// IPPrefix retorna o identificador da rede do IP com o tamanho de prefixo
+// informado, ex: 2001:db8::1 com prefixo 64 vira 2001:db8::/64. Com o prefixo
+// completo (32 ou 128) retorna o próprio IP normalizado.
+func IPPrefix(ip string, prefixIPv4, prefixIPv6 int) (string, error) {
+	parsed := parseIP(ip)
+	if parsed == nil {
+		return "", ErrInvalidIP
+	}
+
+	bits, prefix := FullPrefixIPv6, prefixIPv6
+	if len(parsed) == net.IPv4len {
+		bits, prefix = FullPrefixIPv4, prefixIPv4
+	}
+
+	if prefix <= 0 || prefix >= bits {
+		return parsed.String(), nil
+	}
+
+	network := parsed.Mask(net.CIDRMask(prefix, bits))
+	return fmt.Sprintf("%s/%d", network, prefix), nil
+}
@@ -0,0 +1,71 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIPPrefix(t *testing.T) {
+	tests := []struct {
+		name       string
+		ip         string
+		prefixIPv4 int
+		prefixIPv6 int
+		expected   string
+		wantErr    bool
+	}{
+		{
+			name:       "IPv6 agregado em /64",
+			ip:         "2001:db8:1:2:aaaa:bbbb:cccc:dddd",
+			prefixIPv4: 32,
+			prefixIPv6: 64,
+			expected:   "2001:db8:1:2::/64",
+		},
+		{
+			name:       "IPv4 agregado em /24",
+			ip:         "203.0.113.57",
+			prefixIPv4: 24,
+			prefixIPv6: 64,
+			expected:   "203.0.113.0/24",
+		},
+		{
+			name:       "IPv6 mapeado usa o prefixo IPv4",
+			ip:         "::ffff:203.0.113.57",
+			prefixIPv4: 24,
+			prefixIPv6: 64,
+			expected:   "203.0.113.0/24",
+		},
+		{
+			name:       "Prefixo completo mantém o IP",
+			ip:         "2001:DB8::1",
+			prefixIPv4: 32,
+			prefixIPv6: 128,
+			expected:   "2001:db8::1",
+		},
+		{
+			name:       "Prefixo zero mantém o IP",
+			ip:         "203.0.113.57",
+			prefixIPv4: 0,
+			prefixIPv6: 0,
+			expected:   "203.0.113.57",
+		},
+		{
+			name:    "IP inválido",
+			ip:      "invalid-ip",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefix, err := IPPrefix(tt.ip, tt.prefixIPv4, tt.prefixIPv6)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidIP)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, prefix)
+		})
+	}
+}
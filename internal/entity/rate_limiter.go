@@ -32,9 +32,11 @@ func NewRateLimiter(ip, token string) (*RateLimiter, error) {
 	}
 
 	if ip != "" {
-		if err := validateIP(ip); err != nil {
+		normalized, err := validateIP(ip)
+		if err != nil {
 			return nil, err
 		}
+		ip = normalized
 	}
 
 	return &RateLimiter{
@@ -54,12 +56,22 @@ func (r *RateLimiter) IncrementRequests() {
 
 // Block bloqueia o limitador por um determinado tempo
 func (r *RateLimiter) Block(duration time.Duration) {
+	r.BlockAt(time.Now(), duration)
+}
+
+// BlockAt bloqueia o limitador por um determinado tempo a partir de now
+func (r *RateLimiter) BlockAt(now time.Time, duration time.Duration) {
 	r.Blocked = true
-	r.BlockedUntil = time.Now().Add(duration)
+	r.BlockedUntil = now.Add(duration)
 }
 
 // IsBlocked verifica se o limitador está bloqueado
 func (r *RateLimiter) IsBlocked() bool {
+	return r.IsBlockedAt(time.Now())
+}
+
+// IsBlockedAt verifica se o limitador está bloqueado no instante now
+func (r *RateLimiter) IsBlockedAt(now time.Time) bool {
 	if !r.Blocked {
 		return false
 	}
@@ -68,7 +80,7 @@ func (r *RateLimiter) IsBlocked() bool {
 		return false
 	}
 
-	if time.Now().After(r.BlockedUntil) {
+	if now.After(r.BlockedUntil) {
 		r.Blocked = false
 		r.BlockedUntil = time.Time{}
 		return false
@@ -85,22 +97,46 @@ func (r *RateLimiter) Reset() {
 	r.BlockedUntil = time.Time{}
 }
 
+// RollWindow zera o contador quando now pertence a uma janela diferente
+// da janela da última requisição. As janelas são alinhadas ao múltiplo de window.
+func (r *RateLimiter) RollWindow(now time.Time, window time.Duration) {
+	if window <= 0 {
+		return
+	}
+
+	if !now.Truncate(window).Equal(r.LastRequest.Truncate(window)) {
+		r.Requests = 0
+	}
+}
+
 // UpdateLastRequest atualiza o timestamp da última requisição
 func (r *RateLimiter) UpdateLastRequest() {
 	r.LastRequest = time.Now()
 }
 
-// validateIP valida se o IP é válido
-func validateIP(ip string) error {
-	if ip == "" {
-		return errors.New("IP inválido")
+// validateIP valida se o IP é válido e retorna sua forma canônica. IPv6
+// mapeados para IPv4 (::ffff:192.0.2.1) são convertidos para IPv4, para que o
+// mesmo cliente sempre tenha o mesmo identificador.
+func validateIP(ip string) (string, error) {
+	parsed := parseIP(ip)
+	if parsed == nil {
+		return "", ErrInvalidIP
 	}
 
-	if net.ParseIP(ip) == nil {
-		return errors.New("IP inválido")
+	return parsed.String(), nil
+}
+
+// parseIP interpreta o IP, usando a forma de 4 bytes para IPv4 e IPv6 mapeados
+func parseIP(ip string) net.IP {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return nil
 	}
 
-	return nil
+	if ipv4 := parsed.To4(); ipv4 != nil {
+		return ipv4
+	}
+	return parsed
 }
 
 func (r *RateLimiter) Unblock() {
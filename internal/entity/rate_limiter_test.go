@@ -106,7 +106,7 @@ func TestRateLimiter_IsBlocked(t *testing.T) {
 	assert.False(t, limiter.IsBlocked())
 
 	// Bloquear
-	limiter.Block(1)
+	limiter.Block(time.Second)
 	assert.True(t, limiter.IsBlocked())
 
 	// Esperar o bloqueio expirar
@@ -143,21 +143,49 @@ func TestRateLimiter_UpdateLastRequest(t *testing.T) {
 	assert.True(t, limiter.LastRequest.After(now) || limiter.LastRequest.Equal(now))
 }
 
+func TestRateLimiter_RollWindow(t *testing.T) {
+	limiter, err := NewRateLimiter("192.168.1.1", "")
+	require.NoError(t, err)
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter.Requests = 5
+	limiter.LastRequest = start.Add(200 * time.Millisecond)
+
+	// Mesma janela de 1 segundo: mantém o contador
+	limiter.RollWindow(start.Add(900*time.Millisecond), time.Second)
+	assert.Equal(t, int64(5), limiter.Requests)
+
+	// Próxima janela: zera o contador
+	limiter.RollWindow(start.Add(time.Second), time.Second)
+	assert.Equal(t, int64(0), limiter.Requests)
+
+	// Janela inválida não altera o contador
+	limiter.Requests = 3
+	limiter.RollWindow(start.Add(time.Hour), 0)
+	assert.Equal(t, int64(3), limiter.Requests)
+}
+
 func TestRateLimiter_ValidateIP(t *testing.T) {
 	tests := []struct {
-		name    string
-		ip      string
-		wantErr bool
+		name     string
+		ip       string
+		expected string
+		wantErr  bool
 	}{
 		{
-			name:    "IP válido IPv4",
-			ip:      "192.168.1.1",
-			wantErr: false,
+			name:     "IP válido IPv4",
+			ip:       "192.168.1.1",
+			expected: "192.168.1.1",
 		},
 		{
-			name:    "IP válido IPv6",
-			ip:      "2001:0db8:85a3:0000:0000:8a2e:0370:7334",
-			wantErr: false,
+			name:     "IP válido IPv6",
+			ip:       "2001:0db8:85a3:0000:0000:8a2e:0370:7334",
+			expected: "2001:db8:85a3::8a2e:370:7334",
+		},
+		{
+			name:     "IPv6 mapeado para IPv4",
+			ip:       "::ffff:192.168.1.1",
+			expected: "192.168.1.1",
 		},
 		{
 			name:    "IP inválido",
@@ -173,11 +201,12 @@ func TestRateLimiter_ValidateIP(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := validateIP(tt.ip)
+			ip, err := validateIP(tt.ip)
 			if tt.wantErr {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, ip)
 			}
 		})
 	}
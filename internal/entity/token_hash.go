@@ -0,0 +1,46 @@
+package entity
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// HashedTokenPrefix identifica os tokens já convertidos em hash
+const HashedTokenPrefix = "hmac_"
+
+// TokenHasher converte tokens de API em um HMAC-SHA256 com um segredo, para
+// que os tokens não sejam gravados em claro no armazenamento
+type TokenHasher struct {
+	secret []byte
+}
+
+// NewTokenHasher cria o hasher de tokens com o segredo informado
+func NewTokenHasher(secret []byte) (*TokenHasher, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("segredo do hash de tokens vazio")
+	}
+
+	return &TokenHasher{secret: secret}, nil
+}
+
+// Hash retorna o identificador do token: o prefixo hmac_ seguido do
+// HMAC-SHA256 em hexadecimal
+func (h *TokenHasher) Hash(token string) string {
+	mac := hmac.New(sha256.New, h.secret)
+	mac.Write([]byte(token))
+	return HashedTokenPrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// IsHashedToken verifica se o identificador já é o hash de um token
+func IsHashedToken(identifier string) bool {
+	digest, found := strings.CutPrefix(identifier, HashedTokenPrefix)
+	if !found || len(digest) != sha256.Size*2 {
+		return false
+	}
+
+	_, err := hex.DecodeString(digest)
+	return err == nil
+}
@@ -0,0 +1,30 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenHasher(t *testing.T) {
+	hasher, err := NewTokenHasher([]byte("secret"))
+	require.NoError(t, err)
+
+	hash := hasher.Hash("abc123")
+	assert.Equal(t, hash, hasher.Hash("abc123"))
+	assert.NotEqual(t, hash, hasher.Hash("abc124"))
+	assert.NotContains(t, hash, "abc123")
+	assert.True(t, IsHashedToken(hash))
+
+	// Outro segredo gera outro hash
+	other, err := NewTokenHasher([]byte("other"))
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, other.Hash("abc123"))
+
+	assert.False(t, IsHashedToken("abc123"))
+	assert.False(t, IsHashedToken(HashedTokenPrefix+"abc123"))
+
+	_, err = NewTokenHasher(nil)
+	assert.Error(t, err)
+}
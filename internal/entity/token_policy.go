@@ -0,0 +1,28 @@
+package entity
+
+import "errors"
+
+// TokenPolicy representa os limites próprios de um token de API, usados no
+// lugar dos limites globais por token
+type TokenPolicy struct {
+	Token string `json:"token"`
+	// Plan é o plano contratado pelo cliente (ex: basic, premium)
+	Plan string `json:"plan,omitempty"`
+	// Limit é o número máximo de requisições por janela. Zero usa o limite global
+	Limit int `json:"limit"`
+	// BlockDuration é o tempo de bloqueio em segundos. Zero usa o tempo global
+	BlockDuration int `json:"block_duration,omitempty"`
+}
+
+// Validate verifica se a política de token é válida
+func (p *TokenPolicy) Validate() error {
+	if p.Token == "" {
+		return ErrInvalidToken
+	}
+
+	if p.Limit < 0 || p.BlockDuration < 0 {
+		return errors.New("limite e tempo de bloqueio do token não podem ser negativos")
+	}
+
+	return nil
+}
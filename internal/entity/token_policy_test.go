@@ -0,0 +1,50 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  TokenPolicy
+		wantErr bool
+	}{
+		{
+			name:   "Valid policy",
+			policy: TokenPolicy{Token: "abc123", Plan: "premium", Limit: 1000, BlockDuration: 60},
+		},
+		{
+			name:   "Only plan",
+			policy: TokenPolicy{Token: "abc123", Plan: "basic"},
+		},
+		{
+			name:    "Empty token",
+			policy:  TokenPolicy{Limit: 100},
+			wantErr: true,
+		},
+		{
+			name:    "Negative limit",
+			policy:  TokenPolicy{Token: "abc123", Limit: -1},
+			wantErr: true,
+		},
+		{
+			name:    "Negative block duration",
+			policy:  TokenPolicy{Token: "abc123", BlockDuration: -1},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,430 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/JMKobayashi/Rate-Limiter-GO/internal/entity"
+	"github.com/JMKobayashi/Rate-Limiter-GO/internal/repository"
+)
+
+const (
+	// DefaultCircuitFailureRatio é a taxa de falhas padrão que abre o circuito
+	DefaultCircuitFailureRatio = 0.5
+	// DefaultCircuitMinRequests é o número mínimo padrão de operações na
+	// janela antes de a taxa de falhas ser avaliada
+	DefaultCircuitMinRequests = 20
+	// DefaultCircuitWindow é a janela padrão de medição da taxa de falhas
+	DefaultCircuitWindow = 10 * time.Second
+	// DefaultCircuitOpenTimeout é o tempo padrão em que o circuito fica aberto
+	// antes de enviar operações de teste
+	DefaultCircuitOpenTimeout = 5 * time.Second
+	// DefaultCircuitProbes é o número padrão de operações de teste bem-sucedidas
+	// necessárias para fechar o circuito
+	DefaultCircuitProbes = 3
+)
+
+// CircuitState é o estado do circuit breaker
+type CircuitState string
+
+const (
+	// CircuitClosed envia todas as operações ao repositório e mede as falhas
+	CircuitClosed CircuitState = "closed"
+	// CircuitOpen rejeita as operações com ErrCircuitOpen, sem consultar o repositório
+	CircuitOpen CircuitState = "open"
+	// CircuitHalfOpen envia apenas algumas operações de teste ao repositório
+	CircuitHalfOpen CircuitState = "half_open"
+)
+
+// CircuitStats descreve o estado do circuit breaker para health checks e métricas
+type CircuitStats struct {
+	// State é o estado atual do circuito
+	State CircuitState `json:"state"`
+	// Since é o instante da última mudança de estado
+	Since time.Time `json:"since"`
+	// Requests é o número de operações enviadas ao repositório no estado atual
+	// (na janela atual, quando fechado)
+	Requests int64 `json:"requests"`
+	// Failures é o número de falhas no estado atual (na janela atual, quando fechado)
+	Failures int64 `json:"failures"`
+	// TotalFailures é o total de falhas do repositório desde o início
+	TotalFailures int64 `json:"total_failures"`
+	// TotalRejected é o total de operações rejeitadas com o circuito aberto
+	TotalRejected int64 `json:"total_rejected"`
+	// TotalOpened é o número de vezes que o circuito foi aberto
+	TotalOpened int64 `json:"total_opened"`
+}
+
+// CircuitBreakerRepository envolve um repositório, como o Redis, com um
+// circuit breaker. Fechado, o circuito mede a taxa de falhas em janelas
+// fixas e abre quando ela atinge o limite. Aberto, as operações falham
+// imediatamente com ErrCircuitOpen, que é tratado pela política de falhas,
+// em vez de esperar o tempo limite do repositório. Depois do tempo aberto,
+// o circuito fica meio aberto e envia algumas operações de teste: uma falha
+// o abre de novo e o sucesso de todas o fecha.
+type CircuitBreakerRepository struct {
+	repo           fullRepository
+	failureRatio   float64
+	minRequests    int64
+	window         time.Duration
+	openTimeout    time.Duration
+	probes         int64
+	onStateChange  func(from, to CircuitState)
+	now            func() time.Time
+	mu             sync.Mutex
+	state          CircuitState
+	generation     uint64
+	expiry         time.Time
+	stats          CircuitStats
+	probeSuccesses int64
+}
+
+// CircuitBreakerOption configura parâmetros opcionais do CircuitBreakerRepository
+type CircuitBreakerOption func(*CircuitBreakerRepository)
+
+// WithCircuitFailureThreshold abre o circuito quando a taxa de falhas da
+// janela atinge ratio, depois de pelo menos minRequests operações
+func WithCircuitFailureThreshold(ratio float64, minRequests int) CircuitBreakerOption {
+	return func(b *CircuitBreakerRepository) {
+		if ratio > 0 && ratio <= 1 {
+			b.failureRatio = ratio
+		}
+		if minRequests > 0 {
+			b.minRequests = int64(minRequests)
+		}
+	}
+}
+
+// WithCircuitWindow define a janela de medição da taxa de falhas
+func WithCircuitWindow(window time.Duration) CircuitBreakerOption {
+	return func(b *CircuitBreakerRepository) {
+		if window > 0 {
+			b.window = window
+		}
+	}
+}
+
+// WithCircuitOpenTimeout define por quanto tempo o circuito fica aberto
+// antes de enviar operações de teste
+func WithCircuitOpenTimeout(timeout time.Duration) CircuitBreakerOption {
+	return func(b *CircuitBreakerRepository) {
+		if timeout > 0 {
+			b.openTimeout = timeout
+		}
+	}
+}
+
+// WithCircuitProbes define quantas operações de teste são enviadas com o
+// circuito meio aberto; o circuito fecha quando todas têm sucesso
+func WithCircuitProbes(probes int) CircuitBreakerOption {
+	return func(b *CircuitBreakerRepository) {
+		if probes > 0 {
+			b.probes = int64(probes)
+		}
+	}
+}
+
+// WithCircuitStateChange registra uma função chamada a cada mudança de
+// estado, por exemplo para registrar no log ou atualizar métricas
+func WithCircuitStateChange(fn func(from, to CircuitState)) CircuitBreakerOption {
+	return func(b *CircuitBreakerRepository) {
+		b.onStateChange = fn
+	}
+}
+
+// WithCircuitClock define a função usada para obter o horário atual. Uma
+// função nil é ignorada.
+func WithCircuitClock(now func() time.Time) CircuitBreakerOption {
+	return func(b *CircuitBreakerRepository) {
+		if now != nil {
+			b.now = now
+		}
+	}
+}
+
+// NewCircuitBreakerRepository envolve o repositório com o circuit breaker.
+// O repositório deve suportar todas as operações do repositório em memória.
+func NewCircuitBreakerRepository(repo repository.RateLimiterRepository, opts ...CircuitBreakerOption) (*CircuitBreakerRepository, error) {
+	full, ok := repo.(fullRepository)
+	if !ok {
+		return nil, fmt.Errorf("repositório %T não suporta todas as operações do circuit breaker", repo)
+	}
+
+	b := &CircuitBreakerRepository{
+		repo:         full,
+		failureRatio: DefaultCircuitFailureRatio,
+		minRequests:  DefaultCircuitMinRequests,
+		window:       DefaultCircuitWindow,
+		openTimeout:  DefaultCircuitOpenTimeout,
+		probes:       DefaultCircuitProbes,
+		now:          time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	now := b.now()
+	b.state = CircuitClosed
+	b.expiry = now.Add(b.window)
+	b.stats.State = CircuitClosed
+	b.stats.Since = now
+
+	return b, nil
+}
+
+// State retorna o estado atual do circuito
+func (b *CircuitBreakerRepository) State() CircuitState {
+	return b.Stats().State
+}
+
+// Stats retorna o estado e os contadores do circuito
+func (b *CircuitBreakerRepository) Stats() CircuitStats {
+	b.mu.Lock()
+	changed := b.refresh(b.now())
+	stats := b.stats
+	b.mu.Unlock()
+
+	b.notify(changed)
+	return stats
+}
+
+// stateChange descreve uma mudança de estado a ser notificada fora do lock
+type stateChange struct {
+	from, to CircuitState
+}
+
+// refresh avança o estado de acordo com o horário: uma nova janela quando o
+// circuito está fechado, ou o estado meio aberto quando o tempo aberto acaba.
+// Deve ser chamado com o lock.
+func (b *CircuitBreakerRepository) refresh(now time.Time) []stateChange {
+	switch b.state {
+	case CircuitClosed:
+		if !now.Before(b.expiry) {
+			b.generation++
+			b.expiry = now.Add(b.window)
+			b.stats.Requests = 0
+			b.stats.Failures = 0
+		}
+	case CircuitOpen:
+		if !now.Before(b.expiry) {
+			return b.setState(CircuitHalfOpen, now)
+		}
+	}
+	return nil
+}
+
+// setState muda o estado do circuito e zera os contadores do estado.
+// Deve ser chamado com o lock.
+func (b *CircuitBreakerRepository) setState(state CircuitState, now time.Time) []stateChange {
+	from := b.state
+	b.state = state
+	b.generation++
+	b.probeSuccesses = 0
+	b.stats.State = state
+	b.stats.Since = now
+	b.stats.Requests = 0
+	b.stats.Failures = 0
+
+	switch state {
+	case CircuitClosed:
+		b.expiry = now.Add(b.window)
+	case CircuitOpen:
+		b.expiry = now.Add(b.openTimeout)
+		b.stats.TotalOpened++
+	default:
+		b.expiry = time.Time{}
+	}
+
+	return []stateChange{{from: from, to: state}}
+}
+
+// notify chama a função de mudança de estado, fora do lock
+func (b *CircuitBreakerRepository) notify(changes []stateChange) {
+	if b.onStateChange == nil {
+		return
+	}
+	for _, change := range changes {
+		b.onStateChange(change.from, change.to)
+	}
+}
+
+// before verifica se a operação pode ser enviada ao repositório e retorna a
+// geração do estado em que ela começou
+func (b *CircuitBreakerRepository) before() (uint64, error) {
+	b.mu.Lock()
+	changed := b.refresh(b.now())
+
+	var err error
+	switch {
+	case b.state == CircuitOpen,
+		b.state == CircuitHalfOpen && b.stats.Requests >= b.probes:
+		b.stats.TotalRejected++
+		err = ErrCircuitOpen
+	default:
+		b.stats.Requests++
+	}
+	generation := b.generation
+	b.mu.Unlock()
+
+	b.notify(changed)
+	return generation, err
+}
+
+// after registra o resultado da operação. Resultados de operações iniciadas
+// em um estado anterior são ignorados.
+func (b *CircuitBreakerRepository) after(generation uint64, err error) {
+	b.mu.Lock()
+	now := b.now()
+	changed := b.refresh(now)
+
+	if generation == b.generation {
+		switch {
+		case err == nil:
+			if b.state == CircuitHalfOpen {
+				b.probeSuccesses++
+				if b.probeSuccesses >= b.probes {
+					changed = append(changed, b.setState(CircuitClosed, now)...)
+				}
+			}
+		case isStorageFailure(err):
+			b.stats.Failures++
+			b.stats.TotalFailures++
+			if b.state == CircuitHalfOpen || b.tripped() {
+				changed = append(changed, b.setState(CircuitOpen, now)...)
+			}
+		default:
+			// Cancelamentos e erros de configuração não indicam a saúde do
+			// repositório; a operação não é contada
+			b.stats.Requests--
+		}
+	}
+	b.mu.Unlock()
+
+	b.notify(changed)
+}
+
+// tripped informa se a taxa de falhas da janela atingiu o limite.
+// Deve ser chamado com o lock.
+func (b *CircuitBreakerRepository) tripped() bool {
+	return b.stats.Requests >= b.minRequests &&
+		float64(b.stats.Failures) >= b.failureRatio*float64(b.stats.Requests)
+}
+
+// withBreaker executa a operação no repositório se o circuito permitir
+func withBreaker[T any](ctx context.Context, b *CircuitBreakerRepository, op func(context.Context) (T, error)) (T, error) {
+	generation, err := b.before()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+
+	result, err := op(ctx)
+	b.after(generation, err)
+	return result, err
+}
+
+// Save salva o rate limiter no repositório
+func (b *CircuitBreakerRepository) Save(ctx context.Context, limiter *entity.RateLimiter) error {
+	_, err := withBreaker(ctx, b, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, b.repo.Save(ctx, limiter)
+	})
+	return err
+}
+
+// Get obtém o rate limiter do repositório
+func (b *CircuitBreakerRepository) Get(ctx context.Context, key string) (*entity.RateLimiter, error) {
+	return withBreaker(ctx, b, func(ctx context.Context) (*entity.RateLimiter, error) {
+		return b.repo.Get(ctx, key)
+	})
+}
+
+// Delete remove o rate limiter do repositório
+func (b *CircuitBreakerRepository) Delete(ctx context.Context, key string) error {
+	_, err := withBreaker(ctx, b, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, b.repo.Delete(ctx, key)
+	})
+	return err
+}
+
+// Consume aplica a janela fixa no repositório
+func (b *CircuitBreakerRepository) Consume(ctx context.Context, req ConsumeRequest) (*ConsumeResult, error) {
+	return withBreaker(ctx, b, func(ctx context.Context) (*ConsumeResult, error) {
+		return b.repo.Consume(ctx, req)
+	})
+}
+
+// ConsumeTokenBucket aplica o token bucket no repositório
+func (b *CircuitBreakerRepository) ConsumeTokenBucket(ctx context.Context, req ConsumeRequest) (*ConsumeResult, error) {
+	return withBreaker(ctx, b, func(ctx context.Context) (*ConsumeResult, error) {
+		return b.repo.ConsumeTokenBucket(ctx, req)
+	})
+}
+
+// ConsumeSlidingWindow aplica o sliding window counter no repositório
+func (b *CircuitBreakerRepository) ConsumeSlidingWindow(ctx context.Context, req ConsumeRequest) (*ConsumeResult, error) {
+	return withBreaker(ctx, b, func(ctx context.Context) (*ConsumeResult, error) {
+		return b.repo.ConsumeSlidingWindow(ctx, req)
+	})
+}
+
+// ConsumeSlidingLog aplica o sliding log no repositório
+func (b *CircuitBreakerRepository) ConsumeSlidingLog(ctx context.Context, req ConsumeRequest) (*ConsumeResult, error) {
+	return withBreaker(ctx, b, func(ctx context.Context) (*ConsumeResult, error) {
+		return b.repo.ConsumeSlidingLog(ctx, req)
+	})
+}
+
+// ConsumeGCRA aplica o GCRA no repositório
+func (b *CircuitBreakerRepository) ConsumeGCRA(ctx context.Context, req ConsumeRequest) (*ConsumeResult, error) {
+	return withBreaker(ctx, b, func(ctx context.Context) (*ConsumeResult, error) {
+		return b.repo.ConsumeGCRA(ctx, req)
+	})
+}
+
+// AcquireSlot ocupa uma vaga de requisição em andamento no repositório
+func (b *CircuitBreakerRepository) AcquireSlot(ctx context.Context, req SlotRequest) (*SlotResult, error) {
+	return withBreaker(ctx, b, func(ctx context.Context) (*SlotResult, error) {
+		return b.repo.AcquireSlot(ctx, req)
+	})
+}
+
+// ReleaseSlot libera a vaga no repositório. Com o circuito aberto, a vaga
+// expira ao final do lease.
+func (b *CircuitBreakerRepository) ReleaseSlot(ctx context.Context, key, slotID string) error {
+	_, err := withBreaker(ctx, b, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, b.repo.ReleaseSlot(ctx, key, slotID)
+	})
+	return err
+}
+
+// ConsumeQuota consome as cotas de longo prazo no repositório
+func (b *CircuitBreakerRepository) ConsumeQuota(ctx context.Context, req QuotaRequest) (*QuotaResult, error) {
+	return withBreaker(ctx, b, func(ctx context.Context) (*QuotaResult, error) {
+		return b.repo.ConsumeQuota(ctx, req)
+	})
+}
+
+// circuitTokenRegistry consulta um registro de tokens guardado no mesmo
+// armazenamento do repositório, compartilhando o estado do circuito
+type circuitTokenRegistry struct {
+	breaker  *CircuitBreakerRepository
+	registry repository.TokenRegistry
+}
+
+// TokenRegistry envolve um registro de tokens guardado no mesmo armazenamento,
+// como o RedisTokenRegistry, com o circuito do repositório: as falhas das
+// consultas contam para a taxa de falhas e, com o circuito aberto, as
+// consultas falham imediatamente com ErrCircuitOpen.
+func (b *CircuitBreakerRepository) TokenRegistry(registry repository.TokenRegistry) repository.TokenRegistry {
+	return &circuitTokenRegistry{breaker: b, registry: registry}
+}
+
+// GetTokenPolicy consulta a política do token se o circuito permitir
+func (r *circuitTokenRegistry) GetTokenPolicy(ctx context.Context, token string) (*entity.TokenPolicy, error) {
+	return withBreaker(ctx, r.breaker, func(ctx context.Context) (*entity.TokenPolicy, error) {
+		return r.registry.GetTokenPolicy(ctx, token)
+	})
+}
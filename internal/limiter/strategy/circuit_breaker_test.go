@@ -0,0 +1,177 @@
+package strategy
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/JMKobayashi/Rate-Limiter-GO/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreakerRepository(t *testing.T) {
+	primary := &failingRepository{fullRepository: NewMemoryRateLimiterRepository().(fullRepository)}
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	var changes []string
+	breaker, err := NewCircuitBreakerRepository(primary,
+		WithCircuitFailureThreshold(0.5, 4),
+		WithCircuitWindow(10*time.Second),
+		WithCircuitOpenTimeout(5*time.Second),
+		WithCircuitProbes(2),
+		WithCircuitStateChange(func(from, to CircuitState) {
+			changes = append(changes, string(from)+"->"+string(to))
+		}),
+		WithCircuitClock(func() time.Time { return now }),
+	)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	req := ConsumeRequest{Key: "rate_limiter:ip:192.168.1.1", Limit: 100, Window: time.Minute, Cost: 1, Now: now}
+	consume := func() error {
+		_, err := breaker.Consume(ctx, req)
+		return err
+	}
+
+	// Duas operações com sucesso e duas falhas atingem a taxa de 50%
+	require.NoError(t, consume())
+	require.NoError(t, consume())
+	primary.err = errors.New("redis down")
+	assert.Error(t, consume())
+	assert.Equal(t, CircuitClosed, breaker.State())
+	assert.Error(t, consume())
+	assert.Equal(t, CircuitOpen, breaker.State())
+
+	// Aberto, o circuito não consulta o repositório
+	assert.ErrorIs(t, consume(), ErrCircuitOpen)
+	assert.Equal(t, 4, primary.calls)
+
+	// Depois do tempo aberto, uma falha de teste abre o circuito de novo
+	now = now.Add(5 * time.Second)
+	assert.Equal(t, CircuitHalfOpen, breaker.State())
+	assert.Error(t, consume())
+	assert.Equal(t, CircuitOpen, breaker.State())
+
+	// Com o repositório recuperado, as operações de teste fecham o circuito
+	now = now.Add(5 * time.Second)
+	primary.err = nil
+	require.NoError(t, consume())
+	assert.Equal(t, CircuitHalfOpen, breaker.State())
+	require.NoError(t, consume())
+	assert.Equal(t, CircuitClosed, breaker.State())
+
+	stats := breaker.Stats()
+	assert.Equal(t, int64(3), stats.TotalFailures)
+	assert.Equal(t, int64(1), stats.TotalRejected)
+	assert.Equal(t, int64(2), stats.TotalOpened)
+	assert.Equal(t, now, stats.Since)
+	assert.Equal(t, []string{
+		"closed->open",
+		"open->half_open",
+		"half_open->open",
+		"open->half_open",
+		"half_open->closed",
+	}, changes)
+}
+
+func TestCircuitBreakerRepository_Window(t *testing.T) {
+	primary := &failingRepository{
+		fullRepository: NewMemoryRateLimiterRepository().(fullRepository),
+		err:            errors.New("redis down"),
+	}
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	breaker, err := NewCircuitBreakerRepository(primary,
+		WithCircuitFailureThreshold(0.5, 2),
+		WithCircuitWindow(10*time.Second),
+		WithCircuitClock(func() time.Time { return now }),
+	)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	req := ConsumeRequest{Key: "rate_limiter:ip:192.168.1.1", Limit: 100, Window: time.Minute, Cost: 1, Now: now}
+
+	// Falhas em janelas diferentes não somam para o mínimo de operações
+	_, err = breaker.Consume(ctx, req)
+	assert.Error(t, err)
+	now = now.Add(10 * time.Second)
+	_, err = breaker.Consume(ctx, req)
+	assert.Error(t, err)
+	assert.Equal(t, CircuitClosed, breaker.State())
+
+	// Cancelamentos não são contados como falhas
+	primary.err = context.Canceled
+	_, err = breaker.Consume(ctx, req)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, CircuitClosed, breaker.State())
+	assert.Equal(t, int64(1), breaker.Stats().Requests)
+}
+
+func TestCircuitBreakerRepository_Fallback(t *testing.T) {
+	primary := &failingRepository{
+		fullRepository: NewMemoryRateLimiterRepository().(fullRepository),
+		err:            errors.New("redis down"),
+	}
+	breaker, err := NewCircuitBreakerRepository(primary, WithCircuitFailureThreshold(1, 1))
+	require.NoError(t, err)
+	repo, err := NewFallbackRepository(breaker, time.Nanosecond)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	req := ConsumeRequest{Key: "rate_limiter:ip:192.168.1.1", Limit: 100, Window: time.Minute, Cost: 1, Now: time.Now()}
+
+	// Com o circuito aberto, o fallback em memória responde sem consultar o repositório
+	for i := 0; i < 3; i++ {
+		result, err := repo.Consume(ctx, req)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+	}
+	assert.Equal(t, CircuitOpen, breaker.State())
+	assert.Equal(t, 1, primary.calls)
+}
+
+func TestNewCircuitBreakerRepository_NilClock(t *testing.T) {
+	breaker, err := NewCircuitBreakerRepository(NewMemoryRateLimiterRepository(), WithCircuitClock(nil))
+	require.NoError(t, err)
+
+	// Sem relógio, o circuito usa o horário atual
+	assert.Equal(t, CircuitClosed, breaker.State())
+	assert.WithinDuration(t, time.Now(), breaker.Stats().Since, time.Second)
+}
+
+// failingTokenRegistry simula um registro de tokens indisponível
+type failingTokenRegistry struct {
+	err   error
+	calls int
+}
+
+func (r *failingTokenRegistry) GetTokenPolicy(ctx context.Context, token string) (*entity.TokenPolicy, error) {
+	r.calls++
+	return nil, r.err
+}
+
+func TestCircuitBreakerRepository_TokenRegistry(t *testing.T) {
+	primary := &failingRepository{fullRepository: NewMemoryRateLimiterRepository().(fullRepository)}
+	breaker, err := NewCircuitBreakerRepository(primary, WithCircuitFailureThreshold(1, 2))
+	require.NoError(t, err)
+
+	failing := &failingTokenRegistry{err: errors.New("redis down")}
+	registry := breaker.TokenRegistry(failing)
+	ctx := context.Background()
+
+	// As falhas do registro abrem o circuito compartilhado com o repositório
+	for i := 0; i < 2; i++ {
+		_, err := registry.GetTokenPolicy(ctx, "abc123")
+		assert.EqualError(t, err, "redis down")
+	}
+	assert.Equal(t, CircuitOpen, breaker.State())
+
+	_, err = registry.GetTokenPolicy(ctx, "abc123")
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, 2, failing.calls)
+
+	_, err = breaker.Consume(ctx, ConsumeRequest{Key: "rate_limiter:ip:192.168.1.1", Limit: 1, Window: time.Minute, Cost: 1, Now: time.Now()})
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, 0, primary.calls)
+}
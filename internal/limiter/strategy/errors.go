@@ -5,4 +5,17 @@ import "errors"
 var (
 	// ErrInvalidRepositoryType é retornado quando o tipo de repositório é inválido
 	ErrInvalidRepositoryType = errors.New("tipo de repositório inválido")
+	// ErrUnsupportedAlgorithm é retornado quando o algoritmo é desconhecido ou
+	// não é suportado pelo repositório configurado
+	ErrUnsupportedAlgorithm = errors.New("algoritmo de limitação não suportado")
+	// ErrUnsupportedQuota é retornado quando cotas são configuradas com um
+	// repositório que não as suporta
+	ErrUnsupportedQuota = errors.New("cotas não suportadas pelo repositório")
+	// ErrUnsupportedNamespace é retornado quando um namespace de chaves é
+	// configurado com um repositório sem a operação atômica da janela fixa,
+	// que salva o rate limiter em uma chave sem namespace
+	ErrUnsupportedNamespace = errors.New("namespace de chaves não suportado pelo repositório")
+	// ErrCircuitOpen é retornado quando o circuit breaker está aberto e a
+	// operação não é enviada ao repositório
+	ErrCircuitOpen = errors.New("circuit breaker aberto: repositório indisponível")
 )
@@ -0,0 +1,220 @@
+package strategy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/JMKobayashi/Rate-Limiter-GO/internal/entity"
+	"github.com/JMKobayashi/Rate-Limiter-GO/internal/repository"
+)
+
+// DefaultFallbackRetryInterval é o tempo padrão até o repositório principal
+// voltar a ser consultado depois de uma falha
+const DefaultFallbackRetryInterval = 5 * time.Second
+
+// fullRepository reúne todas as operações dos repositórios de memória e Redis
+type fullRepository interface {
+	repository.RateLimiterRepository
+	AtomicRateLimiterRepository
+	TokenBucketRepository
+	SlidingWindowRepository
+	SlidingLogRepository
+	GCRARepository
+	ConcurrencyRepository
+	QuotaRepository
+}
+
+// FallbackRepository usa um MemoryRateLimiterRepository enquanto o
+// repositório principal, como o Redis, está com falhas. Depois de uma falha,
+// as operações vão direto para a memória até o fim do intervalo de nova
+// tentativa; a próxima operação volta a consultar o repositório principal.
+//
+// Os contadores em memória são locais a cada instância e começam zerados, e
+// não são copiados para o repositório principal quando ele se recupera.
+type FallbackRepository struct {
+	primary        fullRepository
+	fallback       fullRepository
+	retryInterval  time.Duration
+	mu             sync.RWMutex
+	unhealthyUntil time.Time
+	lastErr        error
+	fallbackSlots  map[string]struct{}
+	now            func() time.Time
+}
+
+// NewFallbackRepository envolve o repositório principal com o fallback em
+// memória. O repositório principal deve suportar todas as operações do
+// repositório em memória.
+func NewFallbackRepository(primary repository.RateLimiterRepository, retryInterval time.Duration) (*FallbackRepository, error) {
+	full, ok := primary.(fullRepository)
+	if !ok {
+		return nil, fmt.Errorf("repositório %T não suporta todas as operações do fallback em memória", primary)
+	}
+	if retryInterval <= 0 {
+		retryInterval = DefaultFallbackRetryInterval
+	}
+
+	return &FallbackRepository{
+		primary:       full,
+		fallback:      NewMemoryRateLimiterRepository().(fullRepository),
+		retryInterval: retryInterval,
+		fallbackSlots: make(map[string]struct{}),
+		now:           time.Now,
+	}, nil
+}
+
+// Healthy informa se as operações estão sendo feitas no repositório principal
+func (r *FallbackRepository) Healthy() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return !r.now().Before(r.unhealthyUntil)
+}
+
+// LastError retorna a última falha do repositório principal, ou nil se ele
+// está saudável
+func (r *FallbackRepository) LastError() error {
+	if r.Healthy() {
+		return nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.lastErr
+}
+
+// markUnhealthy desvia as operações para a memória até o fim do intervalo
+func (r *FallbackRepository) markUnhealthy(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.unhealthyUntil = r.now().Add(r.retryInterval)
+	r.lastErr = err
+}
+
+// isStorageFailure informa se o erro indica uma falha do armazenamento. Erros
+// de configuração e o cancelamento da requisição pelo cliente não desviam as
+// operações para a memória.
+func isStorageFailure(err error) bool {
+	return !errors.Is(err, context.Canceled) &&
+		!errors.Is(err, ErrUnsupportedAlgorithm) &&
+		!errors.Is(err, ErrUnsupportedQuota) &&
+		!errors.Is(err, ErrUnsupportedNamespace)
+}
+
+// withFallback executa a operação no repositório principal e, se ele falhar
+// ou estiver indisponível, no repositório em memória
+func withFallback[T any](ctx context.Context, r *FallbackRepository, op func(context.Context, fullRepository) (T, error)) (T, error) {
+	if r.Healthy() {
+		result, err := op(ctx, r.primary)
+		if err == nil || !isStorageFailure(err) {
+			return result, err
+		}
+		r.markUnhealthy(err)
+	}
+
+	// O prazo da requisição pode ter sido consumido pelo repositório principal
+	return op(context.WithoutCancel(ctx), r.fallback)
+}
+
+// Save salva o rate limiter no repositório disponível
+func (r *FallbackRepository) Save(ctx context.Context, limiter *entity.RateLimiter) error {
+	_, err := withFallback(ctx, r, func(ctx context.Context, repo fullRepository) (struct{}, error) {
+		return struct{}{}, repo.Save(ctx, limiter)
+	})
+	return err
+}
+
+// Get obtém o rate limiter do repositório disponível
+func (r *FallbackRepository) Get(ctx context.Context, key string) (*entity.RateLimiter, error) {
+	return withFallback(ctx, r, func(ctx context.Context, repo fullRepository) (*entity.RateLimiter, error) {
+		return repo.Get(ctx, key)
+	})
+}
+
+// Delete remove o rate limiter do repositório disponível
+func (r *FallbackRepository) Delete(ctx context.Context, key string) error {
+	_, err := withFallback(ctx, r, func(ctx context.Context, repo fullRepository) (struct{}, error) {
+		return struct{}{}, repo.Delete(ctx, key)
+	})
+	return err
+}
+
+// Consume aplica a janela fixa no repositório disponível
+func (r *FallbackRepository) Consume(ctx context.Context, req ConsumeRequest) (*ConsumeResult, error) {
+	return withFallback(ctx, r, func(ctx context.Context, repo fullRepository) (*ConsumeResult, error) {
+		return repo.Consume(ctx, req)
+	})
+}
+
+// ConsumeTokenBucket aplica o token bucket no repositório disponível
+func (r *FallbackRepository) ConsumeTokenBucket(ctx context.Context, req ConsumeRequest) (*ConsumeResult, error) {
+	return withFallback(ctx, r, func(ctx context.Context, repo fullRepository) (*ConsumeResult, error) {
+		return repo.ConsumeTokenBucket(ctx, req)
+	})
+}
+
+// ConsumeSlidingWindow aplica o sliding window counter no repositório disponível
+func (r *FallbackRepository) ConsumeSlidingWindow(ctx context.Context, req ConsumeRequest) (*ConsumeResult, error) {
+	return withFallback(ctx, r, func(ctx context.Context, repo fullRepository) (*ConsumeResult, error) {
+		return repo.ConsumeSlidingWindow(ctx, req)
+	})
+}
+
+// ConsumeSlidingLog aplica o sliding log no repositório disponível
+func (r *FallbackRepository) ConsumeSlidingLog(ctx context.Context, req ConsumeRequest) (*ConsumeResult, error) {
+	return withFallback(ctx, r, func(ctx context.Context, repo fullRepository) (*ConsumeResult, error) {
+		return repo.ConsumeSlidingLog(ctx, req)
+	})
+}
+
+// ConsumeGCRA aplica o GCRA no repositório disponível
+func (r *FallbackRepository) ConsumeGCRA(ctx context.Context, req ConsumeRequest) (*ConsumeResult, error) {
+	return withFallback(ctx, r, func(ctx context.Context, repo fullRepository) (*ConsumeResult, error) {
+		return repo.ConsumeGCRA(ctx, req)
+	})
+}
+
+// AcquireSlot ocupa uma vaga de requisição em andamento no repositório
+// disponível, registrando as vagas ocupadas na memória
+func (r *FallbackRepository) AcquireSlot(ctx context.Context, req SlotRequest) (*SlotResult, error) {
+	inFallback := false
+	result, err := withFallback(ctx, r, func(ctx context.Context, repo fullRepository) (*SlotResult, error) {
+		inFallback = repo == r.fallback
+		return repo.AcquireSlot(ctx, req)
+	})
+	if err == nil && result.Acquired && inFallback {
+		r.mu.Lock()
+		r.fallbackSlots[result.SlotID] = struct{}{}
+		r.mu.Unlock()
+	}
+	return result, err
+}
+
+// ReleaseSlot libera a vaga no repositório em que ela foi ocupada. As vagas
+// do repositório principal são liberadas nele mesmo durante uma falha, já
+// que ele pode ter se recuperado; sem isso, elas só expirariam ao final do
+// lease.
+func (r *FallbackRepository) ReleaseSlot(ctx context.Context, key, slotID string) error {
+	r.mu.Lock()
+	_, inFallback := r.fallbackSlots[slotID]
+	delete(r.fallbackSlots, slotID)
+	r.mu.Unlock()
+
+	if inFallback {
+		return r.fallback.ReleaseSlot(ctx, key, slotID)
+	}
+
+	// Uma falha aqui não desvia as operações: a vaga expira ao final do lease
+	return r.primary.ReleaseSlot(ctx, key, slotID)
+}
+
+// ConsumeQuota consome as cotas de longo prazo no repositório disponível
+func (r *FallbackRepository) ConsumeQuota(ctx context.Context, req QuotaRequest) (*QuotaResult, error) {
+	return withFallback(ctx, r, func(ctx context.Context, repo fullRepository) (*QuotaResult, error) {
+		return repo.ConsumeQuota(ctx, req)
+	})
+}
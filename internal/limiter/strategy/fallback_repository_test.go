@@ -0,0 +1,128 @@
+package strategy
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingRepository simula um repositório principal indisponível
+type failingRepository struct {
+	fullRepository
+	err   error
+	calls int
+}
+
+func (r *failingRepository) Consume(ctx context.Context, req ConsumeRequest) (*ConsumeResult, error) {
+	r.calls++
+	if r.err != nil {
+		return nil, r.err
+	}
+	return r.fullRepository.Consume(ctx, req)
+}
+
+func TestFallbackRepository(t *testing.T) {
+	primary := &failingRepository{fullRepository: NewMemoryRateLimiterRepository().(fullRepository)}
+	repo, err := NewFallbackRepository(primary, 10*time.Second)
+	require.NoError(t, err)
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	req := ConsumeRequest{
+		Key:           "rate_limiter:ip:192.168.1.1",
+		Limit:         1,
+		Window:        time.Minute,
+		BlockDuration: time.Minute,
+		Cost:          1,
+		Now:           now,
+	}
+
+	result, err := repo.Consume(ctx, req)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.True(t, repo.Healthy())
+
+	// Com o repositório principal em falha, a memória assume com contadores próprios
+	primary.err = errors.New("redis down")
+	result, err = repo.Consume(ctx, req)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.False(t, repo.Healthy())
+	assert.EqualError(t, repo.LastError(), "redis down")
+
+	// Até o fim do intervalo, o repositório principal não é consultado
+	result, err = repo.Consume(ctx, req)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 2, primary.calls)
+
+	// Depois do intervalo, o repositório principal volta a ser usado
+	primary.err = nil
+	now = now.Add(10 * time.Second)
+	result, err = repo.Consume(ctx, req)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 3, primary.calls)
+	assert.True(t, repo.Healthy())
+	assert.NoError(t, repo.LastError())
+}
+
+func TestFallbackRepository_NonStorageErrors(t *testing.T) {
+	primary := &failingRepository{
+		fullRepository: NewMemoryRateLimiterRepository().(fullRepository),
+		err:            context.Canceled,
+	}
+	repo, err := NewFallbackRepository(primary, time.Minute)
+	require.NoError(t, err)
+
+	// O cancelamento da requisição não desvia as operações para a memória
+	_, err = repo.Consume(context.Background(), ConsumeRequest{Key: "rate_limiter:ip:192.168.1.1", Limit: 1, Window: time.Minute, Cost: 1})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.True(t, repo.Healthy())
+}
+
+func TestNewFallbackRepository_Unsupported(t *testing.T) {
+	_, err := NewFallbackRepository(struct{ RateLimiterRepository }{}, time.Minute)
+	assert.Error(t, err)
+}
+
+func TestFallbackRepository_ReleaseSlot(t *testing.T) {
+	primary := &failingRepository{fullRepository: NewMemoryRateLimiterRepository().(fullRepository)}
+	repo, err := NewFallbackRepository(primary, time.Minute)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	req := SlotRequest{Key: "rate_limiter:ip:192.168.1.1", Limit: 1, Lease: time.Minute, Now: time.Now()}
+
+	// Vaga ocupada no repositório principal, antes da falha
+	acquired, err := repo.AcquireSlot(ctx, req)
+	require.NoError(t, err)
+	require.True(t, acquired.Acquired)
+
+	// Vaga ocupada na memória durante a falha
+	primary.err = errors.New("redis down")
+	_, err = repo.Consume(ctx, ConsumeRequest{Key: "rate_limiter:ip:192.168.1.1", Limit: 1, Window: time.Minute, Cost: 1, Now: req.Now})
+	require.NoError(t, err)
+	require.False(t, repo.Healthy())
+	fallbackSlot, err := repo.AcquireSlot(ctx, req)
+	require.NoError(t, err)
+	require.True(t, fallbackSlot.Acquired)
+
+	// Mesmo com o fallback ativo, a vaga é liberada no repositório principal
+	require.NoError(t, repo.ReleaseSlot(ctx, req.Key, acquired.SlotID))
+	result, err := primary.fullRepository.AcquireSlot(ctx, req)
+	require.NoError(t, err)
+	assert.True(t, result.Acquired)
+
+	// A vaga da memória é liberada na memória
+	require.NoError(t, repo.ReleaseSlot(ctx, req.Key, fallbackSlot.SlotID))
+	result, err = repo.AcquireSlot(ctx, req)
+	require.NoError(t, err)
+	assert.True(t, result.Acquired)
+}
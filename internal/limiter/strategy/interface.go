@@ -2,6 +2,7 @@ package strategy
 
 import (
 	"context"
+	"time"
 
 	"github.com/JMKobayashi/Rate-Limiter-GO/internal/entity"
 )
@@ -13,6 +14,136 @@ type RateLimiterRepository interface {
 	Delete(ctx context.Context, key string) error
 }
 
+// ConsumeRequest descreve uma tentativa de consumo de cota para uma chave
+type ConsumeRequest struct {
+	// Key é a chave base do limitador (ex: rate_limiter:ip:192.168.1.1)
+	Key string
+	// Limit é o número máximo de requisições permitidas por janela
+	Limit int64
+	// Window é a duração da janela de contagem
+	Window time.Duration
+	// Burst é a capacidade máxima de rajada (token bucket). Zero usa Limit
+	Burst int64
+	// BlockDuration é o tempo de bloqueio aplicado quando o limite é excedido
+	BlockDuration time.Duration
+	// Cost é o número de unidades da cota consumidas pela requisição. Zero equivale a 1
+	Cost int64
+	// Now é o instante da requisição
+	Now time.Time
+}
+
+// ConsumeResult é o resultado de uma operação de consumo
+type ConsumeResult struct {
+	Allowed bool
+	// Count é o número de requisições contabilizadas na janela atual
+	Count int64
+	// Remaining é a quantidade de requisições ainda disponíveis
+	Remaining int64
+	// ResetAt é o instante em que a cota estará totalmente disponível novamente
+	ResetAt time.Time
+	// RetryAfter é o tempo até a próxima requisição poder ser aceita
+	RetryAfter   time.Duration
+	BlockedUntil time.Time
+}
+
+// AtomicRateLimiterRepository é implementado por repositórios capazes de
+// incrementar o contador, verificar o limite e aplicar o bloqueio em uma
+// única operação atômica
+type AtomicRateLimiterRepository interface {
+	Consume(ctx context.Context, req ConsumeRequest) (*ConsumeResult, error)
+}
+
+// TokenBucketRepository é implementado por repositórios que suportam o
+// algoritmo token bucket. A capacidade do balde é Burst e a taxa de
+// reabastecimento é Limit a cada Window.
+type TokenBucketRepository interface {
+	ConsumeTokenBucket(ctx context.Context, req ConsumeRequest) (*ConsumeResult, error)
+}
+
+// SlidingWindowRepository é implementado por repositórios que suportam o
+// algoritmo sliding window counter, que evita rajadas na virada das janelas
+type SlidingWindowRepository interface {
+	ConsumeSlidingWindow(ctx context.Context, req ConsumeRequest) (*ConsumeResult, error)
+}
+
+// SlidingLogRepository é implementado por repositórios que suportam o
+// algoritmo sliding log, que guarda o instante de cada requisição aceita
+type SlidingLogRepository interface {
+	ConsumeSlidingLog(ctx context.Context, req ConsumeRequest) (*ConsumeResult, error)
+}
+
+// GCRARepository é implementado por repositórios que suportam o GCRA. A taxa
+// é Limit a cada Window e a tolerância de rajada é Burst (zero usa Limit).
+type GCRARepository interface {
+	ConsumeGCRA(ctx context.Context, req ConsumeRequest) (*ConsumeResult, error)
+}
+
+// SlotRequest descreve uma tentativa de ocupar uma vaga de requisição em andamento
+type SlotRequest struct {
+	// Key é a chave base do limitador (ex: rate_limiter:token:abc123)
+	Key string
+	// Limit é o número máximo de requisições simultâneas
+	Limit int64
+	// Lease é o tempo após o qual uma vaga não liberada expira
+	Lease time.Duration
+	// Now é o instante da requisição
+	Now time.Time
+}
+
+// SlotResult é o resultado de uma tentativa de ocupar uma vaga
+type SlotResult struct {
+	Acquired bool
+	// SlotID identifica a vaga ocupada, usado para liberá-la
+	SlotID string
+	// InFlight é o número de requisições em andamento, incluindo a atual
+	InFlight int64
+}
+
+// ConcurrencyRepository é implementado por repositórios capazes de contar
+// requisições simultâneas por chave. Vagas não liberadas expiram após o
+// Lease, evitando que instâncias encerradas deixem vagas presas.
+type ConcurrencyRepository interface {
+	AcquireSlot(ctx context.Context, req SlotRequest) (*SlotResult, error)
+	ReleaseSlot(ctx context.Context, key, slotID string) error
+}
+
+// Quota é uma cota de longo prazo com reinício alinhado ao calendário
+type Quota struct {
+	// Name identifica a cota (ex: daily, monthly)
+	Name string
+	// Limit é o número máximo de unidades consumidas no período
+	Limit int64
+	// ResetAt é o fim do período atual, quando a cota é reiniciada
+	ResetAt time.Time
+}
+
+// QuotaRequest descreve o consumo de unidades das cotas de uma chave
+type QuotaRequest struct {
+	// Key é a chave base do limitador (ex: rate_limiter:token:abc123)
+	Key string
+	// Cost é o número de unidades consumidas pela requisição. Zero equivale a 1
+	Cost int64
+	// Now é o instante da requisição
+	Now time.Time
+	// Quotas são as cotas verificadas em conjunto
+	Quotas []Quota
+}
+
+// QuotaResult é o resultado do consumo de cotas
+type QuotaResult struct {
+	Allowed bool
+	// Exceeded é o nome da cota esgotada quando a requisição é rejeitada
+	Exceeded string
+	// Used é o número de unidades consumidas em cada cota, na ordem de Quotas
+	Used []int64
+}
+
+// QuotaRepository é implementado por repositórios que suportam cotas de longo
+// prazo. A requisição só é contabilizada se couber em todas as cotas.
+type QuotaRepository interface {
+	ConsumeQuota(ctx context.Context, req QuotaRequest) (*QuotaResult, error)
+}
+
 // StorageStrategy define a interface para estratégias de armazenamento
 type StorageStrategy interface {
 	// Increment incrementa o contador para uma chave específica
@@ -0,0 +1,56 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// AcquireSlot ocupa uma vaga de requisição em andamento para a chave se o
+// número de vagas ocupadas e ainda não expiradas for menor que o limite
+func (r *MemoryRateLimiterRepository) AcquireSlot(ctx context.Context, req SlotRequest) (*SlotResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sweep(req.Now)
+
+	slots, exists := r.slots[req.Key]
+	if !exists {
+		slots = make(map[string]time.Time)
+		r.slots[req.Key] = slots
+	}
+
+	// Descarta as vagas cujo lease expirou
+	for id, expiresAt := range slots {
+		if !expiresAt.After(req.Now) {
+			delete(slots, id)
+		}
+	}
+
+	inFlight := int64(len(slots))
+	if inFlight >= req.Limit {
+		return &SlotResult{Acquired: false, InFlight: inFlight}, nil
+	}
+
+	id := fmt.Sprintf("%d-%d", req.Now.UnixNano(), rand.Int63())
+	slots[id] = req.Now.Add(req.Lease)
+
+	return &SlotResult{Acquired: true, SlotID: id, InFlight: inFlight + 1}, nil
+}
+
+// ReleaseSlot libera uma vaga ocupada por AcquireSlot
+func (r *MemoryRateLimiterRepository) ReleaseSlot(ctx context.Context, key, slotID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	slots, exists := r.slots[key]
+	if !exists {
+		return nil
+	}
+
+	delete(slots, slotID)
+	if len(slots) == 0 {
+		delete(r.slots, key)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package strategy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryRateLimiterRepository_Concurrency(t *testing.T) {
+	repo := NewMemoryRateLimiterRepository().(ConcurrencyRepository)
+	testConcurrency(t, repo, time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+}
+
+// testConcurrency verifica o limite de 2 requisições simultâneas com lease de 30s
+func testConcurrency(t *testing.T, repo ConcurrencyRepository, now time.Time) {
+	ctx := context.Background()
+	req := SlotRequest{
+		Key:   "rate_limiter:token:abc123",
+		Limit: 2,
+		Lease: 30 * time.Second,
+	}
+
+	acquire := func(at time.Duration) *SlotResult {
+		req.Now = now.Add(at)
+		result, err := repo.AcquireSlot(ctx, req)
+		require.NoError(t, err)
+		return result
+	}
+
+	t.Run("Limit of simultaneous requests", func(t *testing.T) {
+		first := acquire(0)
+		assert.True(t, first.Acquired)
+		assert.Equal(t, int64(1), first.InFlight)
+		assert.NotEmpty(t, first.SlotID)
+
+		second := acquire(0)
+		assert.True(t, second.Acquired)
+		assert.Equal(t, int64(2), second.InFlight)
+		assert.NotEqual(t, first.SlotID, second.SlotID)
+
+		result := acquire(0)
+		assert.False(t, result.Acquired)
+		assert.Equal(t, int64(2), result.InFlight)
+		assert.Empty(t, result.SlotID)
+
+		// Liberar uma vaga permite uma nova requisição
+		require.NoError(t, repo.ReleaseSlot(ctx, req.Key, first.SlotID))
+		result = acquire(time.Second)
+		assert.True(t, result.Acquired)
+		assert.Equal(t, int64(2), result.InFlight)
+	})
+
+	t.Run("Expired leases are reclaimed", func(t *testing.T) {
+		// As vagas não liberadas expiram após o lease
+		result := acquire(29 * time.Second)
+		assert.False(t, result.Acquired)
+
+		result = acquire(31 * time.Second)
+		assert.True(t, result.Acquired)
+		assert.Equal(t, int64(1), result.InFlight)
+	})
+}
@@ -0,0 +1,52 @@
+package strategy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/JMKobayashi/Rate-Limiter-GO/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryRateLimiterRepository_Cost(t *testing.T) {
+	testCost(t, NewMemoryRateLimiterRepository(), time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+}
+
+// testCost verifica que cada algoritmo consome Cost unidades da cota, com
+// limite de 10 unidades por segundo e requisições de custo 4
+func testCost(t *testing.T, repo repository.RateLimiterRepository, now time.Time) {
+	algorithms := map[string]func(context.Context, ConsumeRequest) (*ConsumeResult, error){
+		"fixed_window":   repo.(AtomicRateLimiterRepository).Consume,
+		"token_bucket":   repo.(TokenBucketRepository).ConsumeTokenBucket,
+		"sliding_window": repo.(SlidingWindowRepository).ConsumeSlidingWindow,
+		"sliding_log":    repo.(SlidingLogRepository).ConsumeSlidingLog,
+		"gcra":           repo.(GCRARepository).ConsumeGCRA,
+	}
+
+	for name, consume := range algorithms {
+		t.Run(name, func(t *testing.T) {
+			req := ConsumeRequest{
+				Key:    "rate_limiter:token:cost-" + name,
+				Limit:  10,
+				Window: time.Second,
+				Cost:   4,
+				Now:    now,
+			}
+
+			for i := 0; i < 2; i++ {
+				result, err := consume(context.Background(), req)
+				require.NoError(t, err)
+				assert.True(t, result.Allowed, "requisição %d deveria ser permitida", i+1)
+				assert.Equal(t, int64(10-4*(i+1)), result.Remaining)
+			}
+
+			// 8 unidades consumidas: uma requisição de custo 4 excede o limite
+			result, err := consume(context.Background(), req)
+			require.NoError(t, err)
+			assert.False(t, result.Allowed)
+			assert.Greater(t, result.RetryAfter, time.Duration(0))
+		})
+	}
+}
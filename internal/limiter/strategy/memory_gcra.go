@@ -0,0 +1,45 @@
+package strategy
+
+import (
+	"context"
+	"time"
+)
+
+// ConsumeGCRA aplica o GCRA guardando apenas o instante teórico de chegada
+// (TAT) da chave. A requisição é aceita se o novo TAT não ultrapassar o
+// instante atual em mais do que a tolerância de rajada.
+func (r *MemoryRateLimiterRepository) ConsumeGCRA(ctx context.Context, req ConsumeRequest) (*ConsumeResult, error) {
+	if req.Limit <= 0 {
+		return rejectAll(req), nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sweep(req.Now)
+
+	interval := req.Window / time.Duration(req.Limit)
+	tolerance := interval * time.Duration(bucketCapacity(req))
+
+	tat, exists := r.tats[req.Key]
+	if !exists || tat.Before(req.Now) {
+		tat = req.Now
+	}
+
+	newTAT := tat.Add(interval * time.Duration(requestCost(req)))
+	allowAt := newTAT.Add(-tolerance)
+
+	if req.Now.Before(allowAt) {
+		return &ConsumeResult{
+			Allowed:    false,
+			ResetAt:    tat,
+			RetryAfter: allowAt.Sub(req.Now),
+		}, nil
+	}
+
+	r.tats[req.Key] = newTAT
+	return &ConsumeResult{
+		Allowed:   true,
+		Remaining: int64(req.Now.Sub(allowAt) / interval),
+		ResetAt:   newTAT,
+	}, nil
+}
@@ -0,0 +1,75 @@
+package strategy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryRateLimiterRepository_ConsumeGCRA(t *testing.T) {
+	repo := NewMemoryRateLimiterRepository().(GCRARepository)
+	testGCRA(t, repo, time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+}
+
+// testGCRA verifica o GCRA com taxa de 10 req/s e rajada de 5 requisições
+func testGCRA(t *testing.T, repo GCRARepository, now time.Time) {
+	req := ConsumeRequest{
+		Key:    "rate_limiter:token:abc123",
+		Limit:  10,
+		Window: time.Second,
+		Burst:  5,
+	}
+
+	consume := func(at time.Duration) *ConsumeResult {
+		req.Now = now.Add(at)
+		result, err := repo.ConsumeGCRA(context.Background(), req)
+		require.NoError(t, err)
+		return result
+	}
+
+	t.Run("Burst tolerance", func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			result := consume(0)
+			assert.True(t, result.Allowed, "requisição %d deveria ser permitida", i+1)
+			assert.Equal(t, int64(4-i), result.Remaining)
+			assert.Equal(t, now.Add(time.Duration(i+1)*100*time.Millisecond), result.ResetAt)
+		}
+
+		result := consume(0)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, int64(0), result.Remaining)
+		assert.Equal(t, 100*time.Millisecond, result.RetryAfter)
+		assert.Equal(t, now.Add(500*time.Millisecond), result.ResetAt)
+	})
+
+	t.Run("Smooth rate after the burst", func(t *testing.T) {
+		// Uma nova requisição a cada intervalo de emissão (100ms)
+		result := consume(100 * time.Millisecond)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, int64(0), result.Remaining)
+
+		result = consume(150 * time.Millisecond)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, 50*time.Millisecond, result.RetryAfter)
+
+		result = consume(200 * time.Millisecond)
+		assert.True(t, result.Allowed)
+	})
+
+	t.Run("Idle time restores the burst", func(t *testing.T) {
+		result := consume(time.Minute)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, int64(4), result.Remaining)
+	})
+
+	t.Run("Zero limit", func(t *testing.T) {
+		req.Limit = 0
+		result := consume(2 * time.Minute)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, time.Second, result.RetryAfter)
+		assert.Equal(t, req.Now.Add(time.Second), result.ResetAt)
+	})
+}
@@ -0,0 +1,52 @@
+package strategy
+
+import (
+	"context"
+	"time"
+)
+
+// quotaState guarda as unidades consumidas de uma cota no período atual
+type quotaState struct {
+	used    int64
+	resetAt time.Time
+}
+
+// ConsumeQuota contabiliza a requisição em todas as cotas da chave se ela
+// couber em cada uma delas
+func (r *MemoryRateLimiterRepository) ConsumeQuota(ctx context.Context, req QuotaRequest) (*QuotaResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sweep(req.Now)
+
+	cost := quotaCost(req)
+	states := make([]*quotaState, len(req.Quotas))
+	result := &QuotaResult{Allowed: true, Used: make([]int64, len(req.Quotas))}
+
+	for i, quota := range req.Quotas {
+		key := req.Key + ":quota:" + quota.Name
+		state, exists := r.quotas[key]
+		if !exists || !state.resetAt.Equal(quota.ResetAt) {
+			// Inicia um novo período
+			state = &quotaState{resetAt: quota.ResetAt}
+			r.quotas[key] = state
+		}
+		states[i] = state
+		result.Used[i] = state.used
+
+		if result.Allowed && state.used+cost > quota.Limit {
+			result.Allowed = false
+			result.Exceeded = quota.Name
+		}
+	}
+
+	if !result.Allowed {
+		return result, nil
+	}
+
+	for i, state := range states {
+		state.used += cost
+		result.Used[i] = state.used
+	}
+
+	return result, nil
+}
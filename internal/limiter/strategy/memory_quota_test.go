@@ -0,0 +1,67 @@
+package strategy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryRateLimiterRepository_ConsumeQuota(t *testing.T) {
+	repo := NewMemoryRateLimiterRepository().(QuotaRepository)
+	testQuota(t, repo, time.Date(2024, 1, 30, 12, 0, 0, 0, time.UTC))
+}
+
+// testQuota verifica cotas diária de 5 e mensal de 8 unidades a partir do
+// penúltimo dia do mês de now
+func testQuota(t *testing.T, repo QuotaRepository, now time.Time) {
+	consume := func(at time.Time, cost int64) *QuotaResult {
+		result, err := repo.ConsumeQuota(context.Background(), QuotaRequest{
+			Key:  "rate_limiter:token:abc123",
+			Cost: cost,
+			Now:  at,
+			Quotas: []Quota{
+				{Name: "daily", Limit: 5, ResetAt: time.Date(at.Year(), at.Month(), at.Day()+1, 0, 0, 0, 0, at.Location())},
+				{Name: "monthly", Limit: 8, ResetAt: time.Date(at.Year(), at.Month()+1, 1, 0, 0, 0, 0, at.Location())},
+			},
+		})
+		require.NoError(t, err)
+		return result
+	}
+
+	t.Run("Daily quota", func(t *testing.T) {
+		result := consume(now, 3)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, []int64{3, 3}, result.Used)
+
+		result = consume(now, 2)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, []int64{5, 5}, result.Used)
+
+		// A cota diária está esgotada e nada é contabilizado
+		result = consume(now, 1)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, "daily", result.Exceeded)
+		assert.Equal(t, []int64{5, 5}, result.Used)
+	})
+
+	t.Run("Monthly quota", func(t *testing.T) {
+		// O dia seguinte, no mesmo mês, reinicia apenas a cota diária
+		tomorrow := now.AddDate(0, 0, 1)
+		result := consume(tomorrow, 3)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, []int64{3, 8}, result.Used)
+
+		result = consume(tomorrow, 1)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, "monthly", result.Exceeded)
+	})
+
+	t.Run("New month", func(t *testing.T) {
+		result := consume(now.AddDate(0, 0, 2), 5)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, []int64{5, 5}, result.Used)
+	})
+}
@@ -10,16 +10,47 @@ import (
 	"github.com/JMKobayashi/Rate-Limiter-GO/internal/repository"
 )
 
+// memorySweepInterval é o intervalo mínimo entre as varreduras que removem da
+// memória os estados expirados, como o TTL das chaves no Redis
+const memorySweepInterval = time.Minute
+
+// limiterTTL é o tempo que um rate limiter salvo e não bloqueado fica em
+// memória após a última requisição, igual ao TTL usado no Redis
+const limiterTTL = 24 * time.Hour
+
 // MemoryRateLimiterRepository implementa o repositório de rate limiter usando memória
 type MemoryRateLimiterRepository struct {
 	limiters map[string]*entity.RateLimiter
+	windows  map[string]*fixedWindowState
+	buckets  map[string]*tokenBucketState
+	sliding  map[string]*slidingWindowState
+	logs     map[string]*slidingLogState
+	tats     map[string]time.Time
+	slots    map[string]map[string]time.Time
+	quotas   map[string]*quotaState
+	sweepAt  time.Time
 	mu       sync.RWMutex
 }
 
+// fixedWindowState guarda o contador da janela atual de uma chave
+type fixedWindowState struct {
+	count        int64
+	windowStart  time.Time
+	blockedUntil time.Time
+	expiresAt    time.Time
+}
+
 // NewMemoryRateLimiterRepository cria um novo repositório em memória
 func NewMemoryRateLimiterRepository() repository.RateLimiterRepository {
 	return &MemoryRateLimiterRepository{
 		limiters: make(map[string]*entity.RateLimiter),
+		windows:  make(map[string]*fixedWindowState),
+		buckets:  make(map[string]*tokenBucketState),
+		sliding:  make(map[string]*slidingWindowState),
+		logs:     make(map[string]*slidingLogState),
+		tats:     make(map[string]time.Time),
+		slots:    make(map[string]map[string]time.Time),
+		quotas:   make(map[string]*quotaState),
 	}
 }
 
@@ -61,6 +92,129 @@ func (r *MemoryRateLimiterRepository) Delete(ctx context.Context, key string) er
 	return nil
 }
 
+// Consume incrementa o contador da janela atual e aplica o bloqueio de forma atômica
+func (r *MemoryRateLimiterRepository) Consume(ctx context.Context, req ConsumeRequest) (*ConsumeResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sweep(req.Now)
+
+	start := windowStart(req.Now, req.Window)
+	resetAt := start.Add(req.Window)
+
+	state, exists := r.windows[req.Key]
+	if !exists {
+		state = &fixedWindowState{windowStart: start}
+		r.windows[req.Key] = state
+	}
+	defer func() {
+		state.expiresAt = laterOf(resetAt, state.blockedUntil)
+	}()
+
+	// Verifica se está bloqueado
+	if req.Now.Before(state.blockedUntil) {
+		return &ConsumeResult{
+			Allowed:      false,
+			Count:        state.count,
+			ResetAt:      resetAt,
+			RetryAfter:   state.blockedUntil.Sub(req.Now),
+			BlockedUntil: state.blockedUntil,
+		}, nil
+	}
+
+	// Inicia uma nova janela
+	if !state.windowStart.Equal(start) {
+		state.count = 0
+		state.windowStart = start
+	}
+
+	state.count += requestCost(req)
+	if state.count > req.Limit {
+		retryAfter := resetAt.Sub(req.Now)
+		if req.BlockDuration > 0 {
+			state.blockedUntil = req.Now.Add(req.BlockDuration)
+			retryAfter = req.BlockDuration
+		}
+		return &ConsumeResult{
+			Allowed:      false,
+			Count:        state.count,
+			ResetAt:      resetAt,
+			RetryAfter:   retryAfter,
+			BlockedUntil: state.blockedUntil,
+		}, nil
+	}
+
+	return &ConsumeResult{
+		Allowed:   true,
+		Count:     state.count,
+		Remaining: remaining(req.Limit, state.count),
+		ResetAt:   resetAt,
+	}, nil
+}
+
+// sweep remove os estados expirados de todas as chaves, no máximo uma vez a
+// cada memorySweepInterval, para que as chaves de clientes que não voltam
+// não fiquem em memória indefinidamente. Deve ser chamado com o lock.
+func (r *MemoryRateLimiterRepository) sweep(now time.Time) {
+	if now.Before(r.sweepAt) {
+		return
+	}
+	r.sweepAt = now.Add(memorySweepInterval)
+
+	for key, limiter := range r.limiters {
+		if now.After(laterOf(limiter.LastRequest.Add(limiterTTL), limiter.BlockedUntil)) {
+			delete(r.limiters, key)
+		}
+	}
+	for key, state := range r.windows {
+		if !state.expiresAt.After(now) {
+			delete(r.windows, key)
+		}
+	}
+	for key, state := range r.buckets {
+		if !state.expiresAt.After(now) {
+			delete(r.buckets, key)
+		}
+	}
+	for key, state := range r.sliding {
+		if !state.expiresAt.After(now) {
+			delete(r.sliding, key)
+		}
+	}
+	for key, state := range r.logs {
+		if !state.expiresAt.After(now) {
+			delete(r.logs, key)
+		}
+	}
+	for key, tat := range r.tats {
+		if !tat.After(now) {
+			delete(r.tats, key)
+		}
+	}
+	for key, slots := range r.slots {
+		for id, expiresAt := range slots {
+			if !expiresAt.After(now) {
+				delete(slots, id)
+			}
+		}
+		if len(slots) == 0 {
+			delete(r.slots, key)
+		}
+	}
+	for key, state := range r.quotas {
+		if !state.resetAt.After(now) {
+			delete(r.quotas, key)
+		}
+	}
+}
+
+// laterOf retorna o mais tardio dos dois instantes
+func laterOf(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
 // getKey retorna a chave para um rate limiter
 func (r *MemoryRateLimiterRepository) getKey(limiter *entity.RateLimiter) string {
 	if limiter.IP != "" {
@@ -2,6 +2,7 @@ package strategy
 
 import (
 	"context"
+	"sync"
 	"testing"
 	"time"
 
@@ -23,7 +24,7 @@ func TestMemoryRateLimiterRepository(t *testing.T) {
 		require.NoError(t, err)
 
 		// Buscar o limitador
-		got, err := repo.Get(context.Background(), "rate_limit:192.168.1.1")
+		got, err := repo.Get(context.Background(), "rate_limiter:ip:192.168.1.1")
 		require.NoError(t, err)
 		require.NotNil(t, got)
 		assert.Equal(t, limiter.IP, got.IP)
@@ -34,7 +35,7 @@ func TestMemoryRateLimiterRepository(t *testing.T) {
 	})
 
 	t.Run("Get non-existent", func(t *testing.T) {
-		got, err := repo.Get(context.Background(), "rate_limit:non-existent")
+		got, err := repo.Get(context.Background(), "rate_limiter:ip:non-existent")
 		require.NoError(t, err)
 		assert.Nil(t, got)
 	})
@@ -49,11 +50,11 @@ func TestMemoryRateLimiterRepository(t *testing.T) {
 		require.NoError(t, err)
 
 		// Deletar o limitador
-		err = repo.Delete(context.Background(), "rate_limit:192.168.1.1")
+		err = repo.Delete(context.Background(), "rate_limiter:ip:192.168.1.1")
 		require.NoError(t, err)
 
 		// Verificar se foi deletado
-		got, err := repo.Get(context.Background(), "rate_limit:192.168.1.1")
+		got, err := repo.Get(context.Background(), "rate_limiter:ip:192.168.1.1")
 		require.NoError(t, err)
 		assert.Nil(t, got)
 	})
@@ -76,7 +77,7 @@ func TestMemoryRateLimiterRepository(t *testing.T) {
 		require.NoError(t, err)
 
 		// Verificar se foi atualizado
-		got, err := repo.Get(context.Background(), "rate_limit:192.168.1.1")
+		got, err := repo.Get(context.Background(), "rate_limiter:ip:192.168.1.1")
 		require.NoError(t, err)
 		require.NotNil(t, got)
 		assert.Equal(t, int64(10), got.Requests)
@@ -97,7 +98,7 @@ func TestMemoryRateLimiterRepository(t *testing.T) {
 		done := make(chan bool)
 		for i := 0; i < 10; i++ {
 			go func() {
-				got, err := repo.Get(context.Background(), "rate_limit:192.168.1.1")
+				got, err := repo.Get(context.Background(), "rate_limiter:ip:192.168.1.1")
 				require.NoError(t, err)
 				require.NotNil(t, got)
 				done <- true
@@ -122,7 +123,7 @@ func TestMemoryRateLimiterRepository(t *testing.T) {
 		require.NoError(t, err)
 
 		// Verificar se está bloqueado
-		got, err := repo.Get(context.Background(), "rate_limit:192.168.1.1")
+		got, err := repo.Get(context.Background(), "rate_limiter:ip:192.168.1.1")
 		require.NoError(t, err)
 		require.NotNil(t, got)
 		assert.True(t, got.Blocked)
@@ -131,7 +132,7 @@ func TestMemoryRateLimiterRepository(t *testing.T) {
 		time.Sleep(time.Second * 2)
 
 		// Verificar se o bloqueio expirou
-		got, err = repo.Get(context.Background(), "rate_limit:192.168.1.1")
+		got, err = repo.Get(context.Background(), "rate_limiter:ip:192.168.1.1")
 		require.NoError(t, err)
 		require.NotNil(t, got)
 		assert.False(t, got.Blocked)
@@ -148,10 +149,128 @@ func TestMemoryRateLimiterRepository(t *testing.T) {
 		require.NoError(t, err)
 
 		// Buscar o limitador
-		got, err := repo.Get(context.Background(), "rate_limit:test-token")
+		got, err := repo.Get(context.Background(), "rate_limiter:token:test-token")
 		require.NoError(t, err)
 		require.NotNil(t, got)
 		assert.Equal(t, limiter.Token, got.Token)
 		assert.Empty(t, got.IP)
 	})
 }
+
+func TestMemoryRateLimiterRepository_Consume(t *testing.T) {
+	repo := NewMemoryRateLimiterRepository().(AtomicRateLimiterRepository)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	req := ConsumeRequest{
+		Key:           "rate_limiter:ip:192.168.1.1",
+		Limit:         3,
+		Window:        time.Second,
+		BlockDuration: 5 * time.Second,
+		Now:           now,
+	}
+
+	t.Run("Within limit", func(t *testing.T) {
+		for i := int64(1); i <= 3; i++ {
+			result, err := repo.Consume(context.Background(), req)
+			require.NoError(t, err)
+			assert.True(t, result.Allowed)
+			assert.Equal(t, i, result.Count)
+			assert.Equal(t, now.Add(time.Second), result.ResetAt)
+		}
+	})
+
+	t.Run("Exceeds limit and blocks", func(t *testing.T) {
+		result, err := repo.Consume(context.Background(), req)
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, now.Add(5*time.Second), result.BlockedUntil)
+
+		// Nova janela, mas ainda bloqueado
+		req.Now = now.Add(2 * time.Second)
+		result, err = repo.Consume(context.Background(), req)
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+	})
+
+	t.Run("Block expiration starts a new window", func(t *testing.T) {
+		req.Now = now.Add(6 * time.Second)
+		result, err := repo.Consume(context.Background(), req)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, int64(1), result.Count)
+	})
+
+	t.Run("Concurrent consume", func(t *testing.T) {
+		req := ConsumeRequest{
+			Key:    "rate_limiter:ip:10.0.0.1",
+			Limit:  10,
+			Window: time.Second,
+			Now:    now,
+		}
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		allowed := 0
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				result, err := repo.Consume(context.Background(), req)
+				assert.NoError(t, err)
+				if result != nil && result.Allowed {
+					mu.Lock()
+					allowed++
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, 10, allowed)
+	})
+}
+
+func TestMemoryRateLimiterRepository_Sweep(t *testing.T) {
+	repo := NewMemoryRateLimiterRepository().(*MemoryRateLimiterRepository)
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	req := ConsumeRequest{Key: "rate_limiter:ip:192.168.1.1", Limit: 10, Window: time.Minute, Cost: 1, Now: now}
+	_, err := repo.Consume(ctx, req)
+	require.NoError(t, err)
+	_, err = repo.ConsumeTokenBucket(ctx, req)
+	require.NoError(t, err)
+	_, err = repo.ConsumeSlidingWindow(ctx, req)
+	require.NoError(t, err)
+	_, err = repo.ConsumeSlidingLog(ctx, req)
+	require.NoError(t, err)
+	_, err = repo.ConsumeGCRA(ctx, req)
+	require.NoError(t, err)
+	_, err = repo.AcquireSlot(ctx, SlotRequest{Key: req.Key, Limit: 1, Lease: time.Minute, Now: now})
+	require.NoError(t, err)
+	_, err = repo.ConsumeQuota(ctx, QuotaRequest{Key: req.Key, Cost: 1, Now: now, Quotas: []Quota{
+		{Name: "daily", Limit: 10, ResetAt: now.Add(time.Hour)},
+	}})
+	require.NoError(t, err)
+
+	// Uma chave que continua ativa não é removida
+	active := ConsumeRequest{Key: "rate_limiter:ip:192.168.1.2", Limit: 10, Window: time.Hour, BlockDuration: time.Hour, Cost: 11, Now: now}
+	_, err = repo.Consume(ctx, active)
+	require.NoError(t, err)
+
+	// Depois que todos os estados expiram, a próxima operação os remove
+	active.Now = now.Add(2 * time.Hour)
+	active.Cost = 1
+	_, err = repo.Consume(ctx, active)
+	require.NoError(t, err)
+
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+	assert.Len(t, repo.windows, 1)
+	assert.Contains(t, repo.windows, active.Key)
+	assert.Empty(t, repo.buckets)
+	assert.Empty(t, repo.sliding)
+	assert.Empty(t, repo.logs)
+	assert.Empty(t, repo.tats)
+	assert.Empty(t, repo.slots)
+	assert.Empty(t, repo.quotas)
+}
@@ -0,0 +1,108 @@
+package strategy
+
+import (
+	"context"
+	"time"
+)
+
+// slidingLogState guarda os instantes das requisições aceitas de uma chave
+// em um buffer circular, que cresce conforme o uso até a capacidade do limite
+type slidingLogState struct {
+	timestamps []time.Time
+	head       int
+	size       int
+	expiresAt  time.Time
+}
+
+// oldest retorna o instante da requisição mais antiga do log
+func (s *slidingLogState) oldest() time.Time {
+	return s.timestamps[s.head]
+}
+
+// at retorna o instante da i-ésima requisição mais antiga do log
+func (s *slidingLogState) at(i int) time.Time {
+	return s.timestamps[(s.head+i)%len(s.timestamps)]
+}
+
+// newest retorna o instante da requisição mais recente do log
+func (s *slidingLogState) newest() time.Time {
+	return s.timestamps[(s.head+s.size-1)%len(s.timestamps)]
+}
+
+// evict remove as requisições que saíram da janela
+func (s *slidingLogState) evict(cutoff time.Time) {
+	for s.size > 0 && !s.oldest().After(cutoff) {
+		s.head = (s.head + 1) % len(s.timestamps)
+		s.size--
+	}
+}
+
+// push adiciona uma requisição ao final do log, dobrando o buffer quando ele
+// está cheio, sem ultrapassar o limite
+func (s *slidingLogState) push(at time.Time, limit int64) {
+	if s.size == len(s.timestamps) {
+		s.resize(int(min(max(2*int64(len(s.timestamps)), 1), limit)))
+	}
+	s.timestamps[(s.head+s.size)%len(s.timestamps)] = at
+	s.size++
+}
+
+// resize copia as requisições do log, da mais antiga para a mais recente,
+// para um buffer com a capacidade informada, que deve comportar todas elas
+func (s *slidingLogState) resize(capacity int) {
+	timestamps := make([]time.Time, capacity)
+	for i := 0; i < s.size; i++ {
+		timestamps[i] = s.at(i)
+	}
+	s.timestamps = timestamps
+	s.head = 0
+}
+
+// ConsumeSlidingLog registra a requisição, com uma entrada por unidade do
+// seu custo, se ela couber nas Limit unidades da última janela
+func (r *MemoryRateLimiterRepository) ConsumeSlidingLog(ctx context.Context, req ConsumeRequest) (*ConsumeResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sweep(req.Now)
+
+	state, exists := r.logs[req.Key]
+	if !exists {
+		state = &slidingLogState{}
+		r.logs[req.Key] = state
+	}
+
+	// Com um limite menor, o buffer encolhe mantendo as requisições já
+	// registradas, que continuam contando até saírem da janela
+	if int64(len(state.timestamps)) > req.Limit {
+		state.resize(int(max(int64(state.size), req.Limit)))
+	}
+
+	state.evict(req.Now.Add(-req.Window))
+
+	cost := requestCost(req)
+	if int64(state.size)+cost > req.Limit {
+		result := &ConsumeResult{
+			Allowed: false,
+			Count:   int64(state.size),
+			ResetAt: req.Now.Add(req.Window),
+		}
+		if state.size > 0 {
+			// Espera até sair do log a entrada que abre espaço para o custo
+			index := min(int64(state.size), int64(state.size)+cost-req.Limit) - 1
+			result.ResetAt = state.newest().Add(req.Window)
+			result.RetryAfter = state.at(int(index)).Add(req.Window).Sub(req.Now)
+		}
+		return result, nil
+	}
+
+	for i := int64(0); i < cost; i++ {
+		state.push(req.Now, req.Limit)
+	}
+	state.expiresAt = req.Now.Add(req.Window)
+	return &ConsumeResult{
+		Allowed:   true,
+		Count:     int64(state.size),
+		Remaining: remaining(req.Limit, int64(state.size)),
+		ResetAt:   req.Now.Add(req.Window),
+	}, nil
+}
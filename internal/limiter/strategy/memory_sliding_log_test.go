@@ -0,0 +1,97 @@
+package strategy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryRateLimiterRepository_ConsumeSlidingLog(t *testing.T) {
+	repo := NewMemoryRateLimiterRepository().(SlidingLogRepository)
+	testSlidingLog(t, repo, time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+}
+
+// testSlidingLog verifica o sliding log com 5 tentativas a cada 15 minutos
+func testSlidingLog(t *testing.T, repo SlidingLogRepository, now time.Time) {
+	req := ConsumeRequest{
+		Key:    "rate_limiter:ip:192.168.1.1",
+		Limit:  5,
+		Window: 15 * time.Minute,
+	}
+
+	consume := func(at time.Duration) *ConsumeResult {
+		req.Now = now.Add(at)
+		result, err := repo.ConsumeSlidingLog(context.Background(), req)
+		require.NoError(t, err)
+		return result
+	}
+
+	t.Run("Exact limit within the window", func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			result := consume(time.Duration(i) * time.Minute)
+			assert.True(t, result.Allowed, "tentativa %d deveria ser permitida", i+1)
+			assert.Equal(t, int64(i+1), result.Count)
+			assert.Equal(t, int64(4-i), result.Remaining)
+		}
+
+		result := consume(14 * time.Minute)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, int64(5), result.Count)
+		assert.Equal(t, time.Minute, result.RetryAfter)
+		assert.Equal(t, now.Add(19*time.Minute), result.ResetAt)
+	})
+
+	t.Run("Rejected requests are not logged", func(t *testing.T) {
+		// A primeira tentativa (minuto 0) sai da janela no minuto 15
+		result := consume(15 * time.Minute)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, int64(5), result.Count)
+
+		result = consume(15*time.Minute + time.Second)
+		assert.False(t, result.Allowed)
+	})
+
+	t.Run("Window fully expires", func(t *testing.T) {
+		result := consume(time.Hour)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, int64(1), result.Count)
+	})
+}
+
+func TestMemoryRateLimiterRepository_ConsumeSlidingLog_LimitChange(t *testing.T) {
+	repo := NewMemoryRateLimiterRepository().(*MemoryRateLimiterRepository)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	req := ConsumeRequest{Key: "rate_limiter:ip:192.168.1.1", Limit: 1000, Window: time.Minute, Now: now}
+
+	consume := func(limit int64) *ConsumeResult {
+		req.Limit = limit
+		req.Now = req.Now.Add(time.Second)
+		result, err := repo.ConsumeSlidingLog(context.Background(), req)
+		require.NoError(t, err)
+		return result
+	}
+
+	// O buffer cresce conforme o uso, não com o limite
+	for i := 0; i < 3; i++ {
+		assert.True(t, consume(1000).Allowed)
+	}
+	assert.Len(t, repo.logs[req.Key].timestamps, 4)
+
+	// Reduzir o limite mantém as requisições registradas
+	result := consume(2)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, int64(3), result.Count)
+	assert.Equal(t, 58*time.Second, result.RetryAfter)
+	assert.Len(t, repo.logs[req.Key].timestamps, 3)
+
+	// Aumentar o limite também mantém as requisições registradas
+	result = consume(5)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, int64(4), result.Count)
+	assert.True(t, consume(5).Allowed)
+	assert.False(t, consume(5).Allowed)
+	assert.Len(t, repo.logs[req.Key].timestamps, 5)
+}
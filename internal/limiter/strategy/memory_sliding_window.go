@@ -0,0 +1,53 @@
+package strategy
+
+import (
+	"context"
+	"time"
+)
+
+// slidingWindowState guarda os contadores da janela atual e da anterior de uma chave
+type slidingWindowState struct {
+	windowStart time.Time
+	previous    int64
+	current     int64
+	expiresAt   time.Time
+}
+
+// ConsumeSlidingWindow contabiliza a requisição se a estimativa da janela
+// deslizante ainda estiver abaixo do limite
+func (r *MemoryRateLimiterRepository) ConsumeSlidingWindow(ctx context.Context, req ConsumeRequest) (*ConsumeResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sweep(req.Now)
+
+	start := windowStart(req.Now, req.Window)
+
+	state, exists := r.sliding[req.Key]
+	if !exists {
+		state = &slidingWindowState{windowStart: start}
+		r.sliding[req.Key] = state
+	}
+
+	// Avança as janelas: a atual vira a anterior, ou ambas expiram
+	switch {
+	case state.windowStart.Equal(start):
+	case state.windowStart.Add(req.Window).Equal(start):
+		state.previous = state.current
+		state.current = 0
+		state.windowStart = start
+	default:
+		state.previous = 0
+		state.current = 0
+		state.windowStart = start
+	}
+	// A janela atual ainda é usada como a anterior na próxima janela
+	state.expiresAt = start.Add(2 * req.Window)
+
+	cost := requestCost(req)
+	if slidingWindowEstimate(state.previous, state.current, req)+float64(cost) > float64(req.Limit) {
+		return slidingWindowResult(false, state.previous, state.current, req), nil
+	}
+
+	state.current += cost
+	return slidingWindowResult(true, state.previous, state.current, req), nil
+}
@@ -0,0 +1,69 @@
+package strategy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryRateLimiterRepository_ConsumeSlidingWindow(t *testing.T) {
+	repo := NewMemoryRateLimiterRepository().(SlidingWindowRepository)
+	testSlidingWindow(t, repo, time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+}
+
+// testSlidingWindow verifica o comportamento do sliding window counter a
+// partir de now, que deve estar no início de um segundo
+func testSlidingWindow(t *testing.T, repo SlidingWindowRepository, now time.Time) {
+	req := ConsumeRequest{
+		Key:    "rate_limiter:ip:192.168.1.1",
+		Limit:  10,
+		Window: time.Second,
+	}
+
+	consume := func(at time.Duration) *ConsumeResult {
+		req.Now = now.Add(at)
+		result, err := repo.ConsumeSlidingWindow(context.Background(), req)
+		require.NoError(t, err)
+		return result
+	}
+
+	t.Run("Fill the window near its end", func(t *testing.T) {
+		for i := 0; i < 10; i++ {
+			result := consume(900 * time.Millisecond)
+			assert.True(t, result.Allowed, "requisição %d deveria ser permitida", i+1)
+			assert.Equal(t, int64(9-i), result.Remaining)
+		}
+
+		result := consume(900 * time.Millisecond)
+		assert.False(t, result.Allowed)
+	})
+
+	t.Run("No burst across the window boundary", func(t *testing.T) {
+		// Logo após a virada, a janela anterior ainda pesa integralmente
+		result := consume(time.Second)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, 100*time.Millisecond, result.RetryAfter)
+	})
+
+	t.Run("Previous window loses weight over time", func(t *testing.T) {
+		// Na metade da janela, a anterior conta como 5 requisições
+		for i := 0; i < 5; i++ {
+			result := consume(1500 * time.Millisecond)
+			assert.True(t, result.Allowed, "requisição %d deveria ser permitida", i+1)
+		}
+
+		result := consume(1500 * time.Millisecond)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, now.Add(3*time.Second), result.ResetAt)
+	})
+
+	t.Run("Old windows expire", func(t *testing.T) {
+		result := consume(10 * time.Second)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, int64(1), result.Count)
+		assert.Equal(t, int64(9), result.Remaining)
+	})
+}
@@ -0,0 +1,67 @@
+package strategy
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// tokenBucketState guarda os tokens disponíveis de uma chave
+type tokenBucketState struct {
+	tokens     float64
+	lastRefill time.Time
+	expiresAt  time.Time
+}
+
+// ConsumeTokenBucket retira Cost tokens do balde da chave, reabastecendo-o
+// proporcionalmente ao tempo decorrido desde a última requisição
+func (r *MemoryRateLimiterRepository) ConsumeTokenBucket(ctx context.Context, req ConsumeRequest) (*ConsumeResult, error) {
+	// Sem taxa de reabastecimento, a espera e o reinício seriam infinitos
+	if req.Limit <= 0 {
+		return rejectAll(req), nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sweep(req.Now)
+
+	capacity := float64(bucketCapacity(req))
+	cost := float64(requestCost(req))
+	rate := float64(req.Limit) / float64(req.Window)
+
+	state, exists := r.buckets[req.Key]
+	if !exists {
+		state = &tokenBucketState{tokens: capacity, lastRefill: req.Now}
+		r.buckets[req.Key] = state
+	}
+
+	// Reabastece o balde com os tokens gerados desde a última requisição
+	if elapsed := req.Now.Sub(state.lastRefill); elapsed > 0 {
+		state.tokens = math.Min(capacity, state.tokens+float64(elapsed)*rate)
+		state.lastRefill = req.Now
+	}
+
+	result := &ConsumeResult{}
+	if state.tokens >= cost {
+		state.tokens -= cost
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration(math.Ceil((cost - state.tokens) / rate))
+	}
+
+	result.Remaining = int64(state.tokens)
+	result.ResetAt = req.Now.Add(time.Duration(math.Ceil((capacity - state.tokens) / rate)))
+
+	// Com o balde cheio de novo, o estado equivale a uma chave nova
+	state.expiresAt = result.ResetAt
+
+	return result, nil
+}
+
+// bucketCapacity retorna a capacidade do balde, usando Limit quando Burst não é informado
+func bucketCapacity(req ConsumeRequest) int64 {
+	if req.Burst > 0 {
+		return req.Burst
+	}
+	return req.Limit
+}
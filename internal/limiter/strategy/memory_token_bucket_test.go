@@ -0,0 +1,93 @@
+package strategy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryRateLimiterRepository_ConsumeTokenBucket(t *testing.T) {
+	repo := NewMemoryRateLimiterRepository().(TokenBucketRepository)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	req := ConsumeRequest{
+		Key:    "rate_limiter:token:abc123",
+		Limit:  10,
+		Window: time.Second,
+		Burst:  30,
+		Now:    now,
+	}
+
+	t.Run("Burst up to capacity", func(t *testing.T) {
+		for i := 0; i < 30; i++ {
+			result, err := repo.ConsumeTokenBucket(context.Background(), req)
+			require.NoError(t, err)
+			assert.True(t, result.Allowed, "requisição %d deveria ser permitida", i+1)
+			assert.Equal(t, int64(29-i), result.Remaining)
+		}
+
+		result, err := repo.ConsumeTokenBucket(context.Background(), req)
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, 100*time.Millisecond, result.RetryAfter)
+		assert.Equal(t, now.Add(3*time.Second), result.ResetAt)
+	})
+
+	t.Run("Refill at the sustained rate", func(t *testing.T) {
+		// 500ms reabastecem 5 tokens
+		req.Now = now.Add(500 * time.Millisecond)
+		for i := 0; i < 5; i++ {
+			result, err := repo.ConsumeTokenBucket(context.Background(), req)
+			require.NoError(t, err)
+			assert.True(t, result.Allowed)
+		}
+
+		result, err := repo.ConsumeTokenBucket(context.Background(), req)
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+	})
+
+	t.Run("Refill never exceeds capacity", func(t *testing.T) {
+		req.Now = now.Add(time.Hour)
+		result, err := repo.ConsumeTokenBucket(context.Background(), req)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, int64(29), result.Remaining)
+	})
+
+	t.Run("Capacity defaults to limit", func(t *testing.T) {
+		req := ConsumeRequest{
+			Key:    "rate_limiter:token:no-burst",
+			Limit:  2,
+			Window: time.Second,
+			Now:    now,
+		}
+		for i := 0; i < 2; i++ {
+			result, err := repo.ConsumeTokenBucket(context.Background(), req)
+			require.NoError(t, err)
+			assert.True(t, result.Allowed)
+		}
+
+		result, err := repo.ConsumeTokenBucket(context.Background(), req)
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+	})
+
+	t.Run("Zero limit", func(t *testing.T) {
+		req := ConsumeRequest{
+			Key:    "rate_limiter:token:zero",
+			Limit:  0,
+			Burst:  5,
+			Window: time.Second,
+			Now:    now,
+		}
+		result, err := repo.ConsumeTokenBucket(context.Background(), req)
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, time.Second, result.RetryAfter)
+		assert.Equal(t, now.Add(time.Second), result.ResetAt)
+	})
+}
@@ -0,0 +1,105 @@
+package strategy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/JMKobayashi/Rate-Limiter-GO/internal/entity"
+	"github.com/JMKobayashi/Rate-Limiter-GO/internal/repository"
+)
+
+// MemoryTokenRegistry mantém as políticas de token em memória
+type MemoryTokenRegistry struct {
+	policies map[string]*entity.TokenPolicy
+	mu       sync.RWMutex
+}
+
+// tokenRegistryFile é o formato do arquivo de registro de tokens
+type tokenRegistryFile struct {
+	Tokens []entity.TokenPolicy `json:"tokens"`
+}
+
+// NewMemoryTokenRegistry cria um registro de tokens em memória com as políticas informadas
+func NewMemoryTokenRegistry(policies ...entity.TokenPolicy) (*MemoryTokenRegistry, error) {
+	registry := &MemoryTokenRegistry{
+		policies: make(map[string]*entity.TokenPolicy),
+	}
+
+	for _, policy := range policies {
+		if err := registry.SaveTokenPolicy(context.Background(), &policy); err != nil {
+			return nil, err
+		}
+	}
+
+	return registry, nil
+}
+
+// LoadTokenRegistryFile carrega um registro de tokens de um arquivo JSON no formato
+// {"tokens": [{"token": "abc123", "plan": "basic", "limit": 100, "block_duration": 300}]}
+func LoadTokenRegistryFile(path string) (*MemoryTokenRegistry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler registro de tokens: %v", err)
+	}
+
+	var file tokenRegistryFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("erro ao deserializar registro de tokens: %v", err)
+	}
+
+	return NewMemoryTokenRegistry(file.Tokens...)
+}
+
+// GetTokenPolicy retorna a política do token, ou nil se ele não estiver registrado
+func (r *MemoryTokenRegistry) GetTokenPolicy(ctx context.Context, token string) (*entity.TokenPolicy, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	policy, exists := r.policies[token]
+	if !exists {
+		return nil, nil
+	}
+
+	copied := *policy
+	return &copied, nil
+}
+
+// SaveTokenPolicy registra ou atualiza a política de um token
+func (r *MemoryTokenRegistry) SaveTokenPolicy(ctx context.Context, policy *entity.TokenPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	copied := *policy
+	r.policies[policy.Token] = &copied
+	return nil
+}
+
+// chainTokenRegistry consulta vários registros em ordem
+type chainTokenRegistry []repository.TokenRegistry
+
+// NewChainTokenRegistry cria um registro que consulta os registros informados
+// em ordem, retornando a primeira política encontrada
+func NewChainTokenRegistry(registries ...repository.TokenRegistry) repository.TokenRegistry {
+	return chainTokenRegistry(registries)
+}
+
+// GetTokenPolicy retorna a política do primeiro registro que conhece o token
+func (c chainTokenRegistry) GetTokenPolicy(ctx context.Context, token string) (*entity.TokenPolicy, error) {
+	for _, registry := range c {
+		policy, err := registry.GetTokenPolicy(ctx, token)
+		if err != nil {
+			return nil, err
+		}
+		if policy != nil {
+			return policy, nil
+		}
+	}
+	return nil, nil
+}
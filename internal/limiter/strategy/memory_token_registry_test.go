@@ -0,0 +1,97 @@
+package strategy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/JMKobayashi/Rate-Limiter-GO/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tokenRegistry é implementado pelos registros de tokens graváveis
+type tokenRegistry interface {
+	GetTokenPolicy(ctx context.Context, token string) (*entity.TokenPolicy, error)
+	SaveTokenPolicy(ctx context.Context, policy *entity.TokenPolicy) error
+}
+
+func TestMemoryTokenRegistry(t *testing.T) {
+	registry, err := NewMemoryTokenRegistry()
+	require.NoError(t, err)
+	testTokenRegistry(t, registry)
+}
+
+// testTokenRegistry verifica o registro e a consulta de políticas de token
+func testTokenRegistry(t *testing.T, registry tokenRegistry) {
+	ctx := context.Background()
+
+	policy, err := registry.GetTokenPolicy(ctx, "abc123")
+	require.NoError(t, err)
+	assert.Nil(t, policy)
+
+	premium := &entity.TokenPolicy{Token: "abc123", Plan: "premium", Limit: 1000, BlockDuration: 60}
+	require.NoError(t, registry.SaveTokenPolicy(ctx, premium))
+
+	policy, err = registry.GetTokenPolicy(ctx, "abc123")
+	require.NoError(t, err)
+	assert.Equal(t, premium, policy)
+
+	// Políticas inválidas não são registradas
+	assert.Error(t, registry.SaveTokenPolicy(ctx, &entity.TokenPolicy{Token: "abc123", Limit: -1}))
+	policy, err = registry.GetTokenPolicy(ctx, "abc123")
+	require.NoError(t, err)
+	assert.Equal(t, premium, policy)
+}
+
+func TestLoadTokenRegistryFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"tokens": [
+			{"token": "abc123", "plan": "basic", "limit": 100},
+			{"token": "premium", "plan": "premium", "limit": 1000, "block_duration": 60}
+		]
+	}`), 0o600))
+
+	registry, err := LoadTokenRegistryFile(path)
+	require.NoError(t, err)
+
+	policy, err := registry.GetTokenPolicy(context.Background(), "premium")
+	require.NoError(t, err)
+	assert.Equal(t, &entity.TokenPolicy{Token: "premium", Plan: "premium", Limit: 1000, BlockDuration: 60}, policy)
+
+	t.Run("Invalid file", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, []byte(`{"tokens": [{"limit": 100}]}`), 0o600))
+		_, err := LoadTokenRegistryFile(path)
+		assert.Error(t, err)
+
+		_, err = LoadTokenRegistryFile(filepath.Join(t.TempDir(), "missing.json"))
+		assert.Error(t, err)
+	})
+}
+
+func TestChainTokenRegistry(t *testing.T) {
+	file, err := NewMemoryTokenRegistry(entity.TokenPolicy{Token: "abc123", Plan: "basic", Limit: 100})
+	require.NoError(t, err)
+	store, err := NewMemoryTokenRegistry(
+		entity.TokenPolicy{Token: "abc123", Plan: "premium", Limit: 1000},
+		entity.TokenPolicy{Token: "xyz789", Plan: "premium", Limit: 1000},
+	)
+	require.NoError(t, err)
+
+	registry := NewChainTokenRegistry(file, store)
+
+	// O primeiro registro tem precedência
+	policy, err := registry.GetTokenPolicy(context.Background(), "abc123")
+	require.NoError(t, err)
+	assert.Equal(t, "basic", policy.Plan)
+
+	policy, err = registry.GetTokenPolicy(context.Background(), "xyz789")
+	require.NoError(t, err)
+	assert.Equal(t, "premium", policy.Plan)
+
+	policy, err = registry.GetTokenPolicy(context.Background(), "unknown")
+	require.NoError(t, err)
+	assert.Nil(t, policy)
+}
@@ -0,0 +1,16 @@
+package strategy
+
+import "fmt"
+
+// quotaCost retorna as unidades consumidas pela requisição, no mínimo 1
+func quotaCost(req QuotaRequest) int64 {
+	if req.Cost > 0 {
+		return req.Cost
+	}
+	return 1
+}
+
+// quotaKey retorna a chave do contador da cota no período que termina em ResetAt
+func quotaKey(key string, quota Quota) string {
+	return fmt.Sprintf("%s:quota:%s:%d", key, quota.Name, quota.ResetAt.Unix())
+}
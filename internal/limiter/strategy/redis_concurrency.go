@@ -0,0 +1,71 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// acquireSlotScript remove do sorted set as vagas com lease expirado e ocupa
+// uma nova vaga se o limite de requisições simultâneas não foi atingido.
+//
+// KEYS[1]: sorted set com as vagas ocupadas, pontuadas pelo instante de expiração
+// ARGV[1]: limite de requisições simultâneas
+// ARGV[2]: duração do lease em milissegundos
+// ARGV[3]: instante atual em milissegundos
+// ARGV[4]: identificador da vaga
+//
+// Retorna {ocupou, requisições em andamento}
+var acquireSlotScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local lease = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now)
+local count = redis.call('ZCARD', KEYS[1])
+
+if count >= limit then
+	return {0, count}
+end
+
+redis.call('ZADD', KEYS[1], now + lease, ARGV[4])
+redis.call('PEXPIRE', KEYS[1], lease)
+
+return {1, count + 1}
+`)
+
+// AcquireSlot ocupa uma vaga de requisição em andamento usando um sorted set
+// do Redis, compartilhando a contagem entre todas as instâncias
+func (r *RedisRateLimiterRepository) AcquireSlot(ctx context.Context, req SlotRequest) (*SlotResult, error) {
+	id := fmt.Sprintf("%d-%d", req.Now.UnixNano(), rand.Int63())
+
+	values, err := acquireSlotScript.Run(ctx, r.client, []string{req.Key + ":inflight"},
+		req.Limit,
+		req.Lease.Milliseconds(),
+		req.Now.UnixMilli(),
+		id,
+	).Int64Slice()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ocupar vaga no Redis: %v", err)
+	}
+
+	result := &SlotResult{
+		Acquired: values[0] == 1,
+		InFlight: values[1],
+	}
+	if result.Acquired {
+		result.SlotID = id
+	}
+
+	return result, nil
+}
+
+// ReleaseSlot libera uma vaga ocupada por AcquireSlot
+func (r *RedisRateLimiterRepository) ReleaseSlot(ctx context.Context, key, slotID string) error {
+	if err := r.client.ZRem(ctx, key+":inflight", slotID).Err(); err != nil {
+		return fmt.Errorf("erro ao liberar vaga no Redis: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package strategy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisRateLimiterRepository_Concurrency(t *testing.T) {
+	client := setupRedisTest(t)
+	repo := NewRedisRateLimiterRepository(client).(ConcurrencyRepository)
+	testConcurrency(t, repo, time.Now().Truncate(time.Second))
+
+	// As vagas ficam em um único sorted set com expiração
+	ttl, err := client.PTTL(context.Background(), "rate_limiter:token:abc123:inflight").Result()
+	require.NoError(t, err)
+	assert.Greater(t, ttl, time.Duration(0))
+}
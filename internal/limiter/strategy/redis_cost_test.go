@@ -0,0 +1,11 @@
+package strategy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRedisRateLimiterRepository_Cost(t *testing.T) {
+	client := setupRedisTest(t)
+	testCost(t, NewRedisRateLimiterRepository(client), time.Now().Truncate(time.Second))
+}
@@ -0,0 +1,68 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// gcraScript aplica o GCRA guardando somente o instante teórico de chegada
+// (TAT) da chave, com expiração igual ao tempo até o TAT.
+//
+// KEYS[1]: TAT em milissegundos
+// ARGV[1]: tolerância de rajada (em requisições)
+// ARGV[2]: requisições permitidas por janela
+// ARGV[3]: duração da janela em milissegundos
+// ARGV[4]: instante atual em milissegundos
+// ARGV[5]: unidades consumidas pela requisição
+//
+// Retorna {permitido, requisições restantes, espera (ms), tempo até zerar (ms)}
+var gcraScript = redis.NewScript(`
+local burst = tonumber(ARGV[1])
+local interval = tonumber(ARGV[3]) / tonumber(ARGV[2])
+local now = tonumber(ARGV[4])
+local cost = tonumber(ARGV[5])
+
+local tat = tonumber(redis.call('GET', KEYS[1]) or now)
+if tat < now then
+	tat = now
+end
+
+local new_tat = tat + interval * cost
+local allow_at = new_tat - interval * burst
+
+if now < allow_at then
+	return {0, 0, math.ceil(allow_at - now), math.ceil(tat - now)}
+end
+
+redis.call('SET', KEYS[1], tostring(new_tat), 'PX', math.ceil(new_tat - now))
+
+return {1, math.floor((now - allow_at) / interval), 0, math.ceil(new_tat - now)}
+`)
+
+// ConsumeGCRA aplica o GCRA usando um script Lua sobre uma única chave
+func (r *RedisRateLimiterRepository) ConsumeGCRA(ctx context.Context, req ConsumeRequest) (*ConsumeResult, error) {
+	if req.Limit <= 0 {
+		return rejectAll(req), nil
+	}
+
+	values, err := gcraScript.Run(ctx, r.client, []string{req.Key + ":tat"},
+		bucketCapacity(req),
+		req.Limit,
+		req.Window.Milliseconds(),
+		req.Now.UnixMilli(),
+		requestCost(req),
+	).Int64Slice()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consumir GCRA no Redis: %v", err)
+	}
+
+	return &ConsumeResult{
+		Allowed:    values[0] == 1,
+		Remaining:  values[1],
+		RetryAfter: time.Duration(values[2]) * time.Millisecond,
+		ResetAt:    req.Now.Add(time.Duration(values[3]) * time.Millisecond),
+	}, nil
+}
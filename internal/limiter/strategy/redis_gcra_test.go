@@ -0,0 +1,21 @@
+package strategy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisRateLimiterRepository_ConsumeGCRA(t *testing.T) {
+	client := setupRedisTest(t)
+	repo := NewRedisRateLimiterRepository(client).(GCRARepository)
+	testGCRA(t, repo, time.Now().Truncate(time.Second))
+
+	// Apenas uma chave com o TAT é armazenada
+	keys, err := client.Keys(context.Background(), "rate_limiter:token:abc123*").Result()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"rate_limiter:token:abc123:tat"}, keys)
+}
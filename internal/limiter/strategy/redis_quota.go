@@ -0,0 +1,66 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// quotaScript verifica se a requisição cabe em todas as cotas e, nesse caso,
+// incrementa cada contador com expiração no fim do seu período.
+//
+// KEYS[i]: contador da i-ésima cota no período atual
+// ARGV[1]: unidades consumidas pela requisição
+// ARGV[2i]: limite da i-ésima cota
+// ARGV[2i+1]: fim do período da i-ésima cota em milissegundos
+//
+// Retorna {permitido, índice da cota esgotada (base 1, 0 se nenhuma), unidades consumidas...}
+var quotaScript = redis.NewScript(`
+local cost = tonumber(ARGV[1])
+local used = {}
+local exceeded = 0
+
+for i, key in ipairs(KEYS) do
+	used[i] = tonumber(redis.call('GET', key) or '0')
+	if exceeded == 0 and used[i] + cost > tonumber(ARGV[2 * i]) then
+		exceeded = i
+	end
+end
+
+if exceeded > 0 then
+	return {0, exceeded, unpack(used)}
+end
+
+for i, key in ipairs(KEYS) do
+	used[i] = redis.call('INCRBY', key, cost)
+	redis.call('PEXPIREAT', key, ARGV[2 * i + 1])
+end
+
+return {1, 0, unpack(used)}
+`)
+
+// ConsumeQuota contabiliza a requisição nas cotas da chave usando um script Lua
+func (r *RedisRateLimiterRepository) ConsumeQuota(ctx context.Context, req QuotaRequest) (*QuotaResult, error) {
+	keys := make([]string, len(req.Quotas))
+	args := []interface{}{quotaCost(req)}
+	for i, quota := range req.Quotas {
+		keys[i] = quotaKey(req.Key, quota)
+		args = append(args, quota.Limit, quota.ResetAt.UnixMilli())
+	}
+
+	values, err := quotaScript.Run(ctx, r.client, keys, args...).Int64Slice()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consumir cota no Redis: %v", err)
+	}
+
+	result := &QuotaResult{
+		Allowed: values[0] == 1,
+		Used:    values[2:],
+	}
+	if values[1] > 0 {
+		result.Exceeded = req.Quotas[values[1]-1].Name
+	}
+
+	return result, nil
+}
@@ -0,0 +1,28 @@
+package strategy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisRateLimiterRepository_ConsumeQuota(t *testing.T) {
+	client := setupRedisTest(t)
+	repo := NewRedisRateLimiterRepository(client).(QuotaRepository)
+
+	// Os contadores expiram no fim do período, por isso as datas estão no futuro
+	testQuota(t, repo, time.Date(2099, 1, 30, 12, 0, 0, 0, time.UTC))
+
+	// Cada período usa seu próprio contador, com expiração no fim do período
+	keys, err := client.Keys(context.Background(), "rate_limiter:token:abc123:quota:*").Result()
+	require.NoError(t, err)
+	assert.Len(t, keys, 5)
+
+	resetAt := time.Date(2099, 2, 1, 0, 0, 0, 0, time.UTC)
+	ttl, err := client.PTTL(context.Background(), quotaKey("rate_limiter:token:abc123", Quota{Name: "monthly", ResetAt: resetAt})).Result()
+	require.NoError(t, err)
+	assert.InDelta(t, float64(time.Until(resetAt)), float64(ttl), float64(time.Minute))
+}
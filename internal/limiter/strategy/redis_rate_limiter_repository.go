@@ -11,6 +11,47 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
+// consumeScript incrementa o contador da janela atual, verifica o limite e
+// aplica o bloqueio em uma única ida ao Redis.
+//
+// KEYS[1]: contador da janela atual
+// KEYS[2]: marcador de bloqueio
+// ARGV[1]: limite de requisições por janela
+// ARGV[2]: duração da janela em milissegundos
+// ARGV[3]: duração do bloqueio em milissegundos
+// ARGV[4]: instante atual em milissegundos
+// ARGV[5]: unidades consumidas pela requisição
+//
+// Retorna {permitido, contador, bloqueio restante (ms), reset da janela (ms)}
+var consumeScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local block = tonumber(ARGV[3])
+local now = tonumber(ARGV[4])
+local cost = tonumber(ARGV[5])
+local reset = window - (now % window)
+
+local blocked = redis.call('PTTL', KEYS[2])
+if blocked > 0 then
+	local current = tonumber(redis.call('GET', KEYS[1]) or '0')
+	return {0, current, blocked, reset}
+end
+
+local count = redis.call('INCRBY', KEYS[1], cost)
+if count == cost then
+	redis.call('PEXPIRE', KEYS[1], reset)
+end
+
+if count > limit then
+	if block > 0 then
+		redis.call('SET', KEYS[2], 1, 'PX', block)
+	end
+	return {0, count, block, reset}
+end
+
+return {1, count, 0, reset}
+`)
+
 // RedisRateLimiterRepository implementa o repositório de rate limiter usando Redis
 type RedisRateLimiterRepository struct {
 	client *redis.Client
@@ -88,6 +129,41 @@ func (r *RedisRateLimiterRepository) Delete(ctx context.Context, key string) err
 	return nil
 }
 
+// Consume incrementa o contador da janela atual e aplica o bloqueio de forma
+// atômica usando um script Lua. O script é executado via EVALSHA e reenviado
+// com EVAL quando o Redis responde NOSCRIPT.
+func (r *RedisRateLimiterRepository) Consume(ctx context.Context, req ConsumeRequest) (*ConsumeResult, error) {
+	keys := []string{req.Key + ":count", req.Key + ":blocked"}
+	values, err := consumeScript.Run(ctx, r.client, keys,
+		req.Limit,
+		req.Window.Milliseconds(),
+		req.BlockDuration.Milliseconds(),
+		req.Now.UnixMilli(),
+		requestCost(req),
+	).Int64Slice()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consumir rate limiter no Redis: %v", err)
+	}
+
+	reset := time.Duration(values[3]) * time.Millisecond
+	result := &ConsumeResult{
+		Allowed:   values[0] == 1,
+		Count:     values[1],
+		Remaining: remaining(req.Limit, values[1]),
+		ResetAt:   req.Now.Add(reset),
+	}
+	if !result.Allowed {
+		result.RetryAfter = reset
+	}
+	if values[2] > 0 {
+		blocked := time.Duration(values[2]) * time.Millisecond
+		result.BlockedUntil = req.Now.Add(blocked)
+		result.RetryAfter = blocked
+	}
+
+	return result, nil
+}
+
 // getKey retorna a chave do Redis para um rate limiter
 func (r *RedisRateLimiterRepository) getKey(limiter *entity.RateLimiter) string {
 	if limiter.IP != "" {
@@ -2,6 +2,7 @@ package strategy
 
 import (
 	"context"
+	"sync"
 	"testing"
 	"time"
 
@@ -11,10 +12,21 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+// skipIfRedisUnavailable pula o teste quando o Redis não está acessível
+func skipIfRedisUnavailable(t *testing.T, client *redis.Client) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		t.Skipf("Redis indisponível: %v", err)
+	}
+}
+
 func setupRedisTest(t *testing.T) *redis.Client {
 	client := redis.NewClient(&redis.Options{
 		Addr: "localhost:6379",
 	})
+	skipIfRedisUnavailable(t, client)
 
 	// Limpar o banco de dados antes dos testes
 	err := client.FlushDB(context.Background()).Err()
@@ -37,7 +49,7 @@ func TestRedisRateLimiterRepository(t *testing.T) {
 		require.NoError(t, err)
 
 		// Buscar o limitador
-		got, err := repo.Get(context.Background(), "rate_limit:192.168.1.1")
+		got, err := repo.Get(context.Background(), "rate_limiter:ip:192.168.1.1")
 		require.NoError(t, err)
 		require.NotNil(t, got)
 		assert.Equal(t, limiter.IP, got.IP)
@@ -48,7 +60,7 @@ func TestRedisRateLimiterRepository(t *testing.T) {
 	})
 
 	t.Run("Get non-existent", func(t *testing.T) {
-		got, err := repo.Get(context.Background(), "rate_limit:non-existent")
+		got, err := repo.Get(context.Background(), "rate_limiter:ip:non-existent")
 		require.NoError(t, err)
 		assert.Nil(t, got)
 	})
@@ -63,11 +75,11 @@ func TestRedisRateLimiterRepository(t *testing.T) {
 		require.NoError(t, err)
 
 		// Deletar o limitador
-		err = repo.Delete(context.Background(), "rate_limit:192.168.1.1")
+		err = repo.Delete(context.Background(), "rate_limiter:ip:192.168.1.1")
 		require.NoError(t, err)
 
 		// Verificar se foi deletado
-		got, err := repo.Get(context.Background(), "rate_limit:192.168.1.1")
+		got, err := repo.Get(context.Background(), "rate_limiter:ip:192.168.1.1")
 		require.NoError(t, err)
 		assert.Nil(t, got)
 	})
@@ -90,7 +102,7 @@ func TestRedisRateLimiterRepository(t *testing.T) {
 		require.NoError(t, err)
 
 		// Verificar se foi atualizado
-		got, err := repo.Get(context.Background(), "rate_limit:192.168.1.1")
+		got, err := repo.Get(context.Background(), "rate_limiter:ip:192.168.1.1")
 		require.NoError(t, err)
 		require.NotNil(t, got)
 		assert.Equal(t, int64(10), got.Requests)
@@ -111,7 +123,7 @@ func TestRedisRateLimiterRepository(t *testing.T) {
 		done := make(chan bool)
 		for i := 0; i < 10; i++ {
 			go func() {
-				got, err := repo.Get(context.Background(), "rate_limit:192.168.1.1")
+				got, err := repo.Get(context.Background(), "rate_limiter:ip:192.168.1.1")
 				require.NoError(t, err)
 				require.NotNil(t, got)
 				done <- true
@@ -136,7 +148,7 @@ func TestRedisRateLimiterRepository(t *testing.T) {
 		require.NoError(t, err)
 
 		// Verificar se está bloqueado
-		got, err := repo.Get(context.Background(), "rate_limit:192.168.1.1")
+		got, err := repo.Get(context.Background(), "rate_limiter:ip:192.168.1.1")
 		require.NoError(t, err)
 		require.NotNil(t, got)
 		assert.True(t, got.Blocked)
@@ -145,7 +157,7 @@ func TestRedisRateLimiterRepository(t *testing.T) {
 		time.Sleep(time.Second * 2)
 
 		// Verificar se o bloqueio expirou
-		got, err = repo.Get(context.Background(), "rate_limit:192.168.1.1")
+		got, err = repo.Get(context.Background(), "rate_limiter:ip:192.168.1.1")
 		require.NoError(t, err)
 		require.NotNil(t, got)
 		assert.False(t, got.Blocked)
@@ -162,7 +174,7 @@ func TestRedisRateLimiterRepository(t *testing.T) {
 		require.NoError(t, err)
 
 		// Buscar o limitador
-		got, err := repo.Get(context.Background(), "rate_limit:test-token")
+		got, err := repo.Get(context.Background(), "rate_limiter:token:test-token")
 		require.NoError(t, err)
 		require.NotNil(t, got)
 		assert.Equal(t, limiter.Token, got.Token)
@@ -181,35 +193,101 @@ func TestRedisRateLimiterRepository(t *testing.T) {
 		require.NoError(t, err)
 
 		// Verificar TTL
-		ttl, err := client.TTL(context.Background(), "rate_limit:192.168.1.1").Result()
+		ttl, err := client.PTTL(context.Background(), "rate_limiter:ip:192.168.1.1").Result()
 		require.NoError(t, err)
 		assert.True(t, ttl > 0)
 		assert.True(t, ttl <= time.Second*6) // 1 segundo + margem de segurança
 	})
 
-	t.Run("Serialization error", func(t *testing.T) {
-		// Criar um limitador inválido
-		limiter := &entity.RateLimiter{
-			IP:           "192.168.1.1",
-			LastRequest:  time.Now(),
-			BlockedUntil: time.Now(),
-		}
-
-		// Tentar salvar o limitador
-		err := repo.Save(context.Background(), limiter)
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "erro ao serializar")
-	})
-
 	t.Run("Deserialization error", func(t *testing.T) {
 		// Salvar dados inválidos no Redis
-		err := client.Set(context.Background(), "rate_limit:invalid", "invalid-data", 0).Err()
+		err := client.Set(context.Background(), "rate_limiter:ip:invalid", "invalid-data", 0).Err()
 		require.NoError(t, err)
 
 		// Tentar buscar o limitador
-		got, err := repo.Get(context.Background(), "rate_limit:invalid")
+		got, err := repo.Get(context.Background(), "rate_limiter:ip:invalid")
 		assert.Error(t, err)
 		assert.Nil(t, got)
 		assert.Contains(t, err.Error(), "erro ao deserializar")
 	})
 }
+
+func TestRedisRateLimiterRepository_Consume(t *testing.T) {
+	client := setupRedisTest(t)
+	repo := NewRedisRateLimiterRepository(client).(AtomicRateLimiterRepository)
+	now := time.Now().Truncate(time.Second)
+
+	req := ConsumeRequest{
+		Key:           "rate_limiter:ip:192.168.1.1",
+		Limit:         3,
+		Window:        time.Second,
+		BlockDuration: 5 * time.Second,
+		Now:           now,
+	}
+
+	t.Run("Within limit", func(t *testing.T) {
+		for i := int64(1); i <= 3; i++ {
+			result, err := repo.Consume(context.Background(), req)
+			require.NoError(t, err)
+			assert.True(t, result.Allowed)
+			assert.Equal(t, i, result.Count)
+			assert.Equal(t, now.Add(time.Second), result.ResetAt)
+		}
+	})
+
+	t.Run("Exceeds limit and blocks", func(t *testing.T) {
+		result, err := repo.Consume(context.Background(), req)
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, now.Add(5*time.Second), result.BlockedUntil)
+
+		ttl, err := client.TTL(context.Background(), "rate_limiter:ip:192.168.1.1:blocked").Result()
+		require.NoError(t, err)
+		assert.True(t, ttl > 0)
+		assert.True(t, ttl <= 5*time.Second)
+
+		result, err = repo.Consume(context.Background(), req)
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+	})
+
+	t.Run("Script reloaded after flush", func(t *testing.T) {
+		// Remove o script do cache do Redis para forçar o fallback NOSCRIPT
+		require.NoError(t, client.ScriptFlush(context.Background()).Err())
+
+		req := req
+		req.Key = "rate_limiter:ip:10.0.0.2"
+		result, err := repo.Consume(context.Background(), req)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, int64(1), result.Count)
+	})
+
+	t.Run("Concurrent consume", func(t *testing.T) {
+		req := ConsumeRequest{
+			Key:    "rate_limiter:ip:10.0.0.1",
+			Limit:  10,
+			Window: time.Minute,
+			Now:    time.Now(),
+		}
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		allowed := 0
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				result, err := repo.Consume(context.Background(), req)
+				assert.NoError(t, err)
+				if result != nil && result.Allowed {
+					mu.Lock()
+					allowed++
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, 10, allowed)
+	})
+}
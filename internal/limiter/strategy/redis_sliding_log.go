@@ -0,0 +1,78 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// slidingLogScript remove do sorted set as requisições fora da janela e
+// registra a requisição atual, com uma entrada por unidade do seu custo, se
+// ela couber no limite.
+//
+// KEYS[1]: sorted set com os instantes das requisições aceitas
+// ARGV[1]: limite de requisições por janela
+// ARGV[2]: duração da janela em milissegundos
+// ARGV[3]: instante atual em milissegundos
+// ARGV[4]: identificador único da requisição
+// ARGV[5]: unidades consumidas pela requisição
+//
+// Retorna {permitido, requisições na janela, espera (ms), tempo até esvaziar (ms)}
+var slidingLogScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local cost = tonumber(ARGV[5])
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+
+if count + cost > limit then
+	if count == 0 then
+		return {0, 0, 0, window}
+	end
+	local index = math.min(count, count + cost - limit) - 1
+	local freed = redis.call('ZRANGE', KEYS[1], index, index, 'WITHSCORES')
+	local newest = redis.call('ZRANGE', KEYS[1], -1, -1, 'WITHSCORES')
+	return {0, count, tonumber(freed[2]) + window - now, tonumber(newest[2]) + window - now}
+end
+
+for i = 1, cost do
+	redis.call('ZADD', KEYS[1], now, ARGV[4] .. '-' .. i)
+end
+redis.call('PEXPIRE', KEYS[1], window)
+
+return {1, count + cost, 0, window}
+`)
+
+// ConsumeSlidingLog aplica o sliding log usando um sorted set do Redis
+func (r *RedisRateLimiterRepository) ConsumeSlidingLog(ctx context.Context, req ConsumeRequest) (*ConsumeResult, error) {
+	member := fmt.Sprintf("%d-%d", req.Now.UnixNano(), rand.Int63())
+
+	values, err := slidingLogScript.Run(ctx, r.client, []string{req.Key + ":log"},
+		req.Limit,
+		req.Window.Milliseconds(),
+		req.Now.UnixMilli(),
+		member,
+		requestCost(req),
+	).Int64Slice()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consumir sliding log no Redis: %v", err)
+	}
+
+	result := &ConsumeResult{
+		Allowed:    values[0] == 1,
+		Count:      values[1],
+		Remaining:  remaining(req.Limit, values[1]),
+		RetryAfter: time.Duration(values[2]) * time.Millisecond,
+		ResetAt:    req.Now.Add(time.Duration(values[3]) * time.Millisecond),
+	}
+	if !result.Allowed {
+		result.Remaining = 0
+	}
+
+	return result, nil
+}
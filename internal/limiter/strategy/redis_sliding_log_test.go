@@ -0,0 +1,12 @@
+package strategy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRedisRateLimiterRepository_ConsumeSlidingLog(t *testing.T) {
+	client := setupRedisTest(t)
+	repo := NewRedisRateLimiterRepository(client).(SlidingLogRepository)
+	testSlidingLog(t, repo, time.Now().Truncate(time.Second))
+}
@@ -0,0 +1,60 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// slidingWindowScript lê os contadores da janela atual e da anterior e
+// incrementa a atual somente se a estimativa ponderada estiver abaixo do limite.
+//
+// KEYS[1]: contador da janela atual
+// KEYS[2]: contador da janela anterior
+// ARGV[1]: limite de requisições por janela
+// ARGV[2]: duração da janela em milissegundos
+// ARGV[3]: instante atual em milissegundos
+// ARGV[4]: unidades consumidas pela requisição
+//
+// Retorna {permitido, contador anterior, contador atual}
+var slidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local cost = tonumber(ARGV[4])
+
+local current = tonumber(redis.call('GET', KEYS[1]) or '0')
+local previous = tonumber(redis.call('GET', KEYS[2]) or '0')
+local elapsed = (now % window) / window
+
+if previous * (1 - elapsed) + current + cost > limit then
+	return {0, previous, current}
+end
+
+current = redis.call('INCRBY', KEYS[1], cost)
+redis.call('PEXPIRE', KEYS[1], window * 2)
+
+return {1, previous, current}
+`)
+
+// ConsumeSlidingWindow aplica o sliding window counter usando um script Lua
+func (r *RedisRateLimiterRepository) ConsumeSlidingWindow(ctx context.Context, req ConsumeRequest) (*ConsumeResult, error) {
+	index := req.Now.UnixMilli() / req.Window.Milliseconds()
+	keys := []string{
+		fmt.Sprintf("%s:sw:%d", req.Key, index),
+		fmt.Sprintf("%s:sw:%d", req.Key, index-1),
+	}
+
+	values, err := slidingWindowScript.Run(ctx, r.client, keys,
+		req.Limit,
+		req.Window.Milliseconds(),
+		req.Now.UnixMilli(),
+		requestCost(req),
+	).Int64Slice()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consumir sliding window no Redis: %v", err)
+	}
+
+	return slidingWindowResult(values[0] == 1, values[1], values[2], req), nil
+}
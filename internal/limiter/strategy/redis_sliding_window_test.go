@@ -0,0 +1,12 @@
+package strategy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRedisRateLimiterRepository_ConsumeSlidingWindow(t *testing.T) {
+	client := setupRedisTest(t)
+	repo := NewRedisRateLimiterRepository(client).(SlidingWindowRepository)
+	testSlidingWindow(t, repo, time.Now().Truncate(time.Second))
+}
@@ -0,0 +1,81 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenBucketScript reabastece o balde com os tokens gerados desde a última
+// requisição e tenta retirar os tokens da requisição, tudo em uma única
+// operação atômica.
+//
+// KEYS[1]: hash com os campos tokens e ts
+// ARGV[1]: capacidade do balde
+// ARGV[2]: tokens gerados por janela
+// ARGV[3]: duração da janela em milissegundos
+// ARGV[4]: instante atual em milissegundos
+// ARGV[5]: tokens consumidos pela requisição
+//
+// Retorna {permitido, tokens restantes, espera pelos tokens (ms), tempo até encher (ms)}
+var tokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2]) / tonumber(ARGV[3])
+local now = tonumber(ARGV[4])
+local cost = tonumber(ARGV[5])
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = capacity
+	ts = now
+end
+
+if now > ts then
+	tokens = math.min(capacity, tokens + (now - ts) * rate)
+	ts = now
+end
+
+local allowed = 0
+local retry = 0
+if tokens >= cost then
+	tokens = tokens - cost
+	allowed = 1
+else
+	retry = math.ceil((cost - tokens) / rate)
+end
+
+local full = math.ceil((capacity - tokens) / rate)
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', ts)
+redis.call('PEXPIRE', KEYS[1], full + 1000)
+
+return {allowed, math.floor(tokens), retry, full}
+`)
+
+// ConsumeTokenBucket retira Cost tokens do balde da chave usando um script Lua
+func (r *RedisRateLimiterRepository) ConsumeTokenBucket(ctx context.Context, req ConsumeRequest) (*ConsumeResult, error) {
+	if req.Limit <= 0 {
+		return rejectAll(req), nil
+	}
+
+	values, err := tokenBucketScript.Run(ctx, r.client, []string{req.Key + ":bucket"},
+		bucketCapacity(req),
+		req.Limit,
+		req.Window.Milliseconds(),
+		req.Now.UnixMilli(),
+		requestCost(req),
+	).Int64Slice()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consumir token bucket no Redis: %v", err)
+	}
+
+	return &ConsumeResult{
+		Allowed:    values[0] == 1,
+		Remaining:  values[1],
+		RetryAfter: time.Duration(values[2]) * time.Millisecond,
+		ResetAt:    req.Now.Add(time.Duration(values[3]) * time.Millisecond),
+	}, nil
+}
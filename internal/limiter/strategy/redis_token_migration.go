@@ -0,0 +1,120 @@
+package strategy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/JMKobayashi/Rate-Limiter-GO/internal/entity"
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenKeySegment precede o identificador nas chaves de tokens, inclusive
+// nas chaves com namespace das regras por rota
+const tokenKeySegment = ":token:"
+
+// tokenKeySuffix separa o token do sufixo do estado de cada algoritmo em
+// chaves como rate_limiter:token:<token>:count
+var tokenKeySuffix = regexp.MustCompile(`^(.+?)(:count|:blocked|:bucket|:sw:\d+|:log|:tat|:inflight|:quota:[^:]+:\d+)?$`)
+
+// MigrateTokenKeys renomeia as chaves gravadas com o token em claro para o
+// hash do token, preservando o TTL e o estado de cada algoritmo, inclusive as
+// políticas do RedisTokenRegistry. Chaves já convertidas são ignoradas; se a
+// chave com o hash já existir, a chave antiga é removida. Retorna o número de
+// chaves migradas.
+func (r *RedisRateLimiterRepository) MigrateTokenKeys(ctx context.Context, hasher *entity.TokenHasher) (int, error) {
+	migrated := 0
+	iter := r.client.Scan(ctx, 0, "rate_limiter:*token:*", 1000).Iterator()
+	for iter.Next(ctx) {
+		key := iter.Val()
+		newKey, isState, ok := hashedTokenKey(key, hasher)
+		if !ok {
+			continue
+		}
+
+		if err := r.migrateTokenKey(ctx, key, newKey, isState, hasher); err != nil {
+			return migrated, err
+		}
+		migrated++
+	}
+	if err := iter.Err(); err != nil {
+		return migrated, fmt.Errorf("erro ao listar chaves de tokens no Redis: %v", err)
+	}
+
+	// As políticas do registro de tokens não têm o segmento :token:
+	iter = r.client.Scan(ctx, 0, tokenPolicyKeyPrefix+"*", 1000).Iterator()
+	for iter.Next(ctx) {
+		key := iter.Val()
+		token := strings.TrimPrefix(key, tokenPolicyKeyPrefix)
+		if entity.IsHashedToken(token) {
+			continue
+		}
+
+		if err := r.migrateTokenKey(ctx, key, tokenPolicyKeyPrefix+hasher.Hash(token), false, hasher); err != nil {
+			return migrated, err
+		}
+		migrated++
+	}
+	if err := iter.Err(); err != nil {
+		return migrated, fmt.Errorf("erro ao listar políticas de tokens no Redis: %v", err)
+	}
+
+	return migrated, nil
+}
+
+// hashedTokenKey retorna o nome da chave com o hash do token e se ela guarda
+// o estado salvo pelo Save, que também contém o token. ok é falso quando a
+// chave não tem um token em claro.
+func hashedTokenKey(key string, hasher *entity.TokenHasher) (newKey string, isState bool, ok bool) {
+	index := strings.Index(key, tokenKeySegment)
+	if index < 0 {
+		return "", false, false
+	}
+
+	prefix := key[:index+len(tokenKeySegment)]
+	match := tokenKeySuffix.FindStringSubmatch(key[len(prefix):])
+	if match == nil || entity.IsHashedToken(match[1]) {
+		return "", false, false
+	}
+
+	return prefix + hasher.Hash(match[1]) + match[2], match[2] == "", true
+}
+
+// migrateTokenKey move a chave para o nome com o hash do token, substituindo
+// também o token guardado no estado salvo pelo Save
+func (r *RedisRateLimiterRepository) migrateTokenKey(ctx context.Context, key, newKey string, isState bool, hasher *entity.TokenHasher) error {
+	if isState {
+		data, err := r.client.Get(ctx, key).Bytes()
+		if err != nil {
+			if err == redis.Nil {
+				return nil
+			}
+			return fmt.Errorf("erro ao migrar rate limiter no Redis: %v", err)
+		}
+
+		var limiter entity.RateLimiter
+		if err := json.Unmarshal(data, &limiter); err != nil {
+			return fmt.Errorf("erro ao deserializar rate limiter: %v", err)
+		}
+		limiter.Token = hasher.Hash(limiter.Token)
+		if data, err = json.Marshal(limiter); err != nil {
+			return fmt.Errorf("erro ao serializar rate limiter: %v", err)
+		}
+		if err := r.client.SetArgs(ctx, key, data, redis.SetArgs{KeepTTL: true}).Err(); err != nil {
+			return fmt.Errorf("erro ao migrar rate limiter no Redis: %v", err)
+		}
+	}
+
+	renamed, err := r.client.RenameNX(ctx, key, newKey).Result()
+	if err != nil {
+		return fmt.Errorf("erro ao migrar chave de token no Redis: %v", err)
+	}
+	if !renamed {
+		if err := r.client.Del(ctx, key).Err(); err != nil {
+			return fmt.Errorf("erro ao migrar chave de token no Redis: %v", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package strategy
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/JMKobayashi/Rate-Limiter-GO/internal/entity"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisRateLimiterRepository_MigrateTokenKeys(t *testing.T) {
+	client := setupRedisTest(t)
+	repo := NewRedisRateLimiterRepository(client).(*RedisRateLimiterRepository)
+	ctx := context.Background()
+
+	hasher, err := entity.NewTokenHasher([]byte("secret"))
+	require.NoError(t, err)
+	hash := hasher.Hash("abc123")
+
+	// Estado gravado antes do hash dos tokens
+	limiter, err := entity.NewRateLimiter("", "abc123")
+	require.NoError(t, err)
+	require.NoError(t, repo.Save(ctx, limiter))
+	require.NoError(t, client.Set(ctx, "rate_limiter:token:abc123:count", 3, time.Minute).Err())
+	require.NoError(t, client.ZAdd(ctx, "rate_limiter:token:abc123:log", redis.Z{Score: 1, Member: "a"}).Err())
+	require.NoError(t, client.Set(ctx, "rate_limiter:rule:POST:/login:token:abc123:quota:daily:1700000000", 7, time.Hour).Err())
+	require.NoError(t, client.Set(ctx, "rate_limiter:token:other:count", 1, time.Minute).Err())
+	require.NoError(t, client.Set(ctx, "rate_limiter:token:"+hasher.Hash("other")+":count", 5, time.Minute).Err())
+	require.NoError(t, client.Set(ctx, "rate_limiter:ip:192.168.1.1:count", 2, time.Minute).Err())
+	require.NoError(t, NewRedisTokenRegistry(client).SaveTokenPolicy(ctx,
+		&entity.TokenPolicy{Token: "abc123", Plan: "basic", Limit: 2}))
+
+	migrated, err := repo.MigrateTokenKeys(ctx, hasher)
+	require.NoError(t, err)
+	assert.Equal(t, 6, migrated)
+
+	// As chaves com o token em claro não existem mais
+	keys, err := client.Keys(ctx, "*abc123*").Result()
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+
+	count, err := client.Get(ctx, "rate_limiter:token:"+hash+":count").Int()
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+	ttl, err := client.TTL(ctx, "rate_limiter:token:"+hash+":count").Result()
+	require.NoError(t, err)
+	assert.Greater(t, ttl, time.Duration(0))
+
+	exists, err := client.Exists(ctx,
+		"rate_limiter:token:"+hash+":log",
+		"rate_limiter:rule:POST:/login:token:"+hash+":quota:daily:1700000000",
+	).Result()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), exists)
+
+	// O estado salvo guarda o hash no lugar do token
+	data, err := client.Get(ctx, "rate_limiter:token:"+hash).Bytes()
+	require.NoError(t, err)
+	var saved entity.RateLimiter
+	require.NoError(t, json.Unmarshal(data, &saved))
+	assert.Equal(t, hash, saved.Token)
+
+	// A política do token é encontrada pelo registro com o hash
+	policy, err := NewRedisTokenRegistry(client, WithRegistryTokenHasher(hasher)).GetTokenPolicy(ctx, "abc123")
+	require.NoError(t, err)
+	require.NotNil(t, policy)
+	assert.Equal(t, 2, policy.Limit)
+
+	// Se a chave com o hash já existe, ela é mantida
+	count, err = client.Get(ctx, "rate_limiter:token:"+hasher.Hash("other")+":count").Int()
+	require.NoError(t, err)
+	assert.Equal(t, 5, count)
+
+	// Chaves de IP não são alteradas e uma nova execução não migra nada
+	count, err = client.Get(ctx, "rate_limiter:ip:192.168.1.1:count").Int()
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	migrated, err = repo.MigrateTokenKeys(ctx, hasher)
+	require.NoError(t, err)
+	assert.Equal(t, 0, migrated)
+}
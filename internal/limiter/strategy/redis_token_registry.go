@@ -0,0 +1,119 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/JMKobayashi/Rate-Limiter-GO/internal/entity"
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenPolicyKeyPrefix precede o token nas chaves das políticas de token
+const tokenPolicyKeyPrefix = "rate_limiter:token_policy:"
+
+// RedisTokenRegistry guarda as políticas de token no Redis, em um hash por
+// token, permitindo alterar os limites sem reiniciar a aplicação
+type RedisTokenRegistry struct {
+	client *redis.Client
+	hasher *entity.TokenHasher
+}
+
+// RedisTokenRegistryOption configura um RedisTokenRegistry
+type RedisTokenRegistryOption func(*RedisTokenRegistry)
+
+// WithRegistryTokenHasher grava as chaves das políticas com o hash do token,
+// em vez do token em claro. Um hasher nil mantém o token em claro.
+func WithRegistryTokenHasher(hasher *entity.TokenHasher) RedisTokenRegistryOption {
+	return func(r *RedisTokenRegistry) {
+		r.hasher = hasher
+	}
+}
+
+// NewRedisTokenRegistry cria um registro de tokens no Redis
+func NewRedisTokenRegistry(client *redis.Client, opts ...RedisTokenRegistryOption) *RedisTokenRegistry {
+	r := &RedisTokenRegistry{
+		client: client,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// GetTokenPolicy retorna a política do token, ou nil se ele não estiver registrado
+func (r *RedisTokenRegistry) GetTokenPolicy(ctx context.Context, token string) (*entity.TokenPolicy, error) {
+	fields, err := r.client.HGetAll(ctx, r.getKey(token)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("erro ao recuperar política do token no Redis: %v", err)
+	}
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	policy := &entity.TokenPolicy{
+		Token: token,
+		Plan:  fields["plan"],
+	}
+	if policy.Limit, err = atoiField(fields, "limit"); err != nil {
+		return nil, err
+	}
+	if policy.BlockDuration, err = atoiField(fields, "block_duration"); err != nil {
+		return nil, err
+	}
+
+	// O hash pode ter sido editado diretamente no Redis, sem SaveTokenPolicy
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("política do token inválida no Redis: %w", err)
+	}
+
+	return policy, nil
+}
+
+// SaveTokenPolicy registra ou atualiza a política de um token
+func (r *RedisTokenRegistry) SaveTokenPolicy(ctx context.Context, policy *entity.TokenPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+
+	err := r.client.HSet(ctx, r.getKey(policy.Token),
+		"plan", policy.Plan,
+		"limit", policy.Limit,
+		"block_duration", policy.BlockDuration,
+	).Err()
+	if err != nil {
+		return fmt.Errorf("erro ao salvar política do token no Redis: %v", err)
+	}
+
+	return nil
+}
+
+// DeleteTokenPolicy remove a política de um token
+func (r *RedisTokenRegistry) DeleteTokenPolicy(ctx context.Context, token string) error {
+	if err := r.client.Del(ctx, r.getKey(token)).Err(); err != nil {
+		return fmt.Errorf("erro ao remover política do token no Redis: %v", err)
+	}
+	return nil
+}
+
+// getKey retorna a chave do Redis com a política de um token
+func (r *RedisTokenRegistry) getKey(token string) string {
+	if r.hasher != nil {
+		token = r.hasher.Hash(token)
+	}
+	return tokenPolicyKeyPrefix + token
+}
+
+// atoiField converte um campo numérico do hash, considerando zero quando ausente
+func atoiField(fields map[string]string, name string) (int, error) {
+	value, exists := fields[name]
+	if !exists || value == "" {
+		return 0, nil
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("campo %s inválido na política do token: %v", name, err)
+	}
+	return number, nil
+}
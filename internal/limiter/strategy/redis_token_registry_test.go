@@ -0,0 +1,40 @@
+package strategy
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/JMKobayashi/Rate-Limiter-GO/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisTokenRegistry(t *testing.T) {
+	client := setupRedisTest(t)
+	registry := NewRedisTokenRegistry(client)
+	testTokenRegistry(t, registry)
+
+	require.NoError(t, registry.DeleteTokenPolicy(context.Background(), "abc123"))
+	policy, err := registry.GetTokenPolicy(context.Background(), "abc123")
+	require.NoError(t, err)
+	assert.Nil(t, policy)
+}
+
+func TestRedisTokenRegistry_TokenHasher(t *testing.T) {
+	client := setupRedisTest(t)
+	ctx := context.Background()
+
+	hasher, err := entity.NewTokenHasher([]byte("secret"))
+	require.NoError(t, err)
+	registry := NewRedisTokenRegistry(client, WithRegistryTokenHasher(hasher))
+	testTokenRegistry(t, registry)
+
+	// As chaves das políticas não contêm o token em claro
+	keys, err := client.Keys(ctx, "rate_limiter:token_policy:*").Result()
+	require.NoError(t, err)
+	assert.NotEmpty(t, keys)
+	for _, key := range keys {
+		assert.True(t, entity.IsHashedToken(strings.TrimPrefix(key, "rate_limiter:token_policy:")), key)
+	}
+}
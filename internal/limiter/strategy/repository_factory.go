@@ -11,6 +11,7 @@ import (
 // RepositoryFactory é uma fábrica para criar repositórios
 type RepositoryFactory struct {
 	repositories map[RepositoryType]repository.RateLimiterRepository
+	configs      map[RepositoryType]map[string]interface{}
 	mu           sync.RWMutex
 }
 
@@ -18,9 +19,18 @@ type RepositoryFactory struct {
 func NewRepositoryFactory() *RepositoryFactory {
 	return &RepositoryFactory{
 		repositories: make(map[RepositoryType]repository.RateLimiterRepository),
+		configs:      make(map[RepositoryType]map[string]interface{}),
 	}
 }
 
+// Configure define a configuração usada ao criar um tipo de repositório
+func (f *RepositoryFactory) Configure(repoType RepositoryType, config map[string]interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.configs[repoType] = config
+}
+
 // NewRepository cria um novo repositório
 func NewRepository(repoType RepositoryType, config map[string]interface{}) (repository.RateLimiterRepository, error) {
 	switch repoType {
@@ -57,7 +67,10 @@ func (f *RepositoryFactory) GetRepository(repoType RepositoryType) (repository.R
 	}
 
 	// Cria uma nova instância
-	config := make(map[string]interface{})
+	config, exists := f.configs[repoType]
+	if !exists {
+		config = make(map[string]interface{})
+	}
 	repo, err := NewRepository(repoType, config)
 	if err != nil {
 		return nil, err
@@ -62,6 +62,10 @@ func TestNewRepository(t *testing.T) {
 				assert.NoError(t, err)
 				assert.NotNil(t, repo)
 
+				if client, ok := tt.config["client"].(*redis.Client); ok {
+					skipIfRedisUnavailable(t, client)
+				}
+
 				// Testar operações básicas
 				limiter, err := entity.NewRateLimiter("192.168.1.1", "")
 				require.NoError(t, err)
@@ -71,17 +75,17 @@ func TestNewRepository(t *testing.T) {
 				require.NoError(t, err)
 
 				// Buscar
-				got, err := repo.Get(context.Background(), "rate_limit:192.168.1.1")
+				got, err := repo.Get(context.Background(), "rate_limiter:ip:192.168.1.1")
 				require.NoError(t, err)
 				require.NotNil(t, got)
 				assert.Equal(t, limiter.IP, got.IP)
 
 				// Deletar
-				err = repo.Delete(context.Background(), "rate_limit:192.168.1.1")
+				err = repo.Delete(context.Background(), "rate_limiter:ip:192.168.1.1")
 				require.NoError(t, err)
 
 				// Verificar se foi deletado
-				got, err = repo.Get(context.Background(), "rate_limit:192.168.1.1")
+				got, err = repo.Get(context.Background(), "rate_limiter:ip:192.168.1.1")
 				require.NoError(t, err)
 				assert.Nil(t, got)
 			}
@@ -91,6 +95,11 @@ func TestNewRepository(t *testing.T) {
 
 func TestRepositoryFactory_Singleton(t *testing.T) {
 	factory := NewRepositoryFactory()
+	factory.Configure(RedisRepository, map[string]interface{}{
+		"client": redis.NewClient(&redis.Options{
+			Addr: "localhost:6379",
+		}),
+	})
 
 	// Criar primeiro repositório
 	repo1, err := factory.GetRepository(MemoryRepository)
@@ -134,7 +143,7 @@ func TestRepositoryFactory_ConcurrentAccess(t *testing.T) {
 	done := make(chan bool)
 	for i := 0; i < 10; i++ {
 		go func() {
-			got, err := repo.Get(context.Background(), "rate_limit:192.168.1.1")
+			got, err := repo.Get(context.Background(), "rate_limiter:ip:192.168.1.1")
 			require.NoError(t, err)
 			require.NotNil(t, got)
 			done <- true
@@ -0,0 +1,61 @@
+package strategy
+
+import (
+	"math"
+	"time"
+)
+
+// slidingWindowEstimate estima as requisições feitas na última janela,
+// ponderando a janela anterior pela fração dela que ainda está dentro
+// da janela deslizante
+func slidingWindowEstimate(previous, current int64, req ConsumeRequest) float64 {
+	elapsed := float64(req.Now.Sub(windowStart(req.Now, req.Window))) / float64(req.Window)
+	return float64(previous)*(1-elapsed) + float64(current)
+}
+
+// slidingWindowResult monta o resultado a partir dos contadores da janela
+// anterior e da atual, já considerando a requisição quando permitida
+func slidingWindowResult(allowed bool, previous, current int64, req ConsumeRequest) *ConsumeResult {
+	start := windowStart(req.Now, req.Window)
+	windowEnd := start.Add(req.Window)
+	estimate := slidingWindowEstimate(previous, current, req)
+
+	result := &ConsumeResult{
+		Allowed:   allowed,
+		Count:     current,
+		Remaining: remaining(req.Limit, int64(math.Ceil(estimate))),
+		ResetAt:   windowEnd,
+	}
+	if current > 0 {
+		// A janela atual só deixa de contar ao fim da próxima janela
+		result.ResetAt = windowEnd.Add(req.Window)
+	}
+
+	if !allowed {
+		result.RetryAfter = slidingWindowRetryAfter(previous, current, req)
+	}
+
+	return result
+}
+
+// slidingWindowRetryAfter calcula quando a estimativa cairá o suficiente
+// para aceitar a requisição com o seu custo
+func slidingWindowRetryAfter(previous, current int64, req ConsumeRequest) time.Duration {
+	start := windowStart(req.Now, req.Window)
+	free := float64(req.Limit - requestCost(req))
+
+	// Ainda nesta janela, conforme a janela anterior perde peso
+	if previous > 0 && float64(current) <= free {
+		fraction := 1 - (free-float64(current))/float64(previous)
+		at := start.Add(time.Duration(math.Ceil(fraction * float64(req.Window))))
+		return at.Sub(req.Now)
+	}
+
+	// Na próxima janela, quando a janela atual passa a ser a anterior
+	fraction := 0.0
+	if current > 0 {
+		fraction = math.Max(0, 1-free/float64(current))
+	}
+	at := start.Add(req.Window).Add(time.Duration(math.Ceil(fraction * float64(req.Window))))
+	return at.Sub(req.Now)
+}
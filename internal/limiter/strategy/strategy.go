@@ -1,5 +1,10 @@
 package strategy
 
+import (
+	"fmt"
+	"time"
+)
+
 // RepositoryType define o tipo de repositório a ser usado
 type RepositoryType string
 
@@ -9,3 +14,70 @@ const (
 	// MemoryRepository é o tipo para usar memória como persistência
 	MemoryRepository RepositoryType = "memory"
 )
+
+// Algorithm define o algoritmo de limitação a ser usado
+type Algorithm string
+
+const (
+	// FixedWindow conta as requisições em janelas fixas alinhadas ao relógio
+	FixedWindow Algorithm = "fixed_window"
+	// TokenBucket permite rajadas até a capacidade do balde, reabastecido a uma taxa constante
+	TokenBucket Algorithm = "token_bucket"
+	// SlidingWindow estima as requisições da última janela ponderando a janela anterior e a atual
+	SlidingWindow Algorithm = "sliding_window"
+	// SlidingLog registra o instante de cada requisição para uma contagem exata na última janela
+	SlidingLog Algorithm = "sliding_log"
+	// GCRA (generic cell rate algorithm) guarda apenas o instante teórico de chegada da próxima requisição
+	GCRA Algorithm = "gcra"
+)
+
+// ParseAlgorithm converte uma string de configuração em um Algorithm.
+// Uma string vazia resulta em FixedWindow.
+func ParseAlgorithm(value string) (Algorithm, error) {
+	switch Algorithm(value) {
+	case "":
+		return FixedWindow, nil
+	case FixedWindow, TokenBucket, SlidingWindow, SlidingLog, GCRA:
+		return Algorithm(value), nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, value)
+	}
+}
+
+// remaining calcula quantas requisições ainda cabem no limite
+func remaining(limit, used int64) int64 {
+	if used >= limit {
+		return 0
+	}
+	return limit - used
+}
+
+// rejectAll nega a requisição quando o limite não permite nenhuma requisição,
+// sem dividir a janela pelo limite. A espera é de uma janela.
+func rejectAll(req ConsumeRequest) *ConsumeResult {
+	retryAfter := req.Window
+	if retryAfter <= 0 {
+		retryAfter = time.Second
+	}
+	return &ConsumeResult{
+		Allowed:    false,
+		ResetAt:    req.Now.Add(retryAfter),
+		RetryAfter: retryAfter,
+	}
+}
+
+// requestCost retorna as unidades consumidas pela requisição, no mínimo 1
+func requestCost(req ConsumeRequest) int64 {
+	if req.Cost > 0 {
+		return req.Cost
+	}
+	return 1
+}
+
+// windowStart retorna o início da janela que contém now, alinhada à época
+// Unix da mesma forma que os scripts Lua do repositório Redis. A leitura
+// monotônica é descartada para que janelas iguais sejam comparáveis.
+func windowStart(now time.Time, window time.Duration) time.Time {
+	now = now.Round(0)
+	return now.Add(-time.Duration(now.UnixNano() % int64(window)))
+}
@@ -0,0 +1,189 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+const (
+	// HeaderXForwardedFor é a lista de IPs acrescentada por cada proxy
+	HeaderXForwardedFor = "X-Forwarded-For"
+	// HeaderXRealIP é o IP do cliente informado pelo proxy
+	HeaderXRealIP = "X-Real-IP"
+	// HeaderForwarded é o header padronizado pela RFC 7239
+	HeaderForwarded = "Forwarded"
+	// HeaderCFConnectingIP é o IP do cliente informado pela Cloudflare
+	HeaderCFConnectingIP = "CF-Connecting-IP"
+)
+
+// DefaultClientIPHeaders são os headers consultados quando nenhum é configurado
+var DefaultClientIPHeaders = []string{HeaderXForwardedFor, HeaderXRealIP}
+
+// ClientIPResolver determina o IP do cliente confiando nos headers de proxy
+// somente quando a conexão vem de um proxy confiável
+type ClientIPResolver struct {
+	trustedProxies []*net.IPNet
+	headers        []string
+}
+
+// NewClientIPResolver cria um resolvedor de IP. trustedProxies aceita CIDRs
+// ou IPs individuais; headers é a ordem dos headers consultados. Headers
+// diferentes de X-Forwarded-For e Forwarded devem conter um único IP.
+func NewClientIPResolver(trustedProxies []string, headers []string) (*ClientIPResolver, error) {
+	resolver := &ClientIPResolver{}
+	for _, header := range headers {
+		if header = strings.TrimSpace(header); header != "" {
+			resolver.headers = append(resolver.headers, header)
+		}
+	}
+	if len(resolver.headers) == 0 {
+		resolver.headers = DefaultClientIPHeaders
+	}
+
+	for _, proxy := range trustedProxies {
+		network, err := parseNetwork(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("proxy confiável inválido: %w", err)
+		}
+		if network != nil {
+			resolver.trustedProxies = append(resolver.trustedProxies, network)
+		}
+	}
+
+	return resolver, nil
+}
+
+// ClientIP retorna o IP do cliente, ou uma string vazia se ele não puder ser determinado
+func (r *ClientIPResolver) ClientIP(req *http.Request) string {
+	remote := parseIP(req.RemoteAddr)
+	if remote == nil {
+		return ""
+	}
+
+	// Conexões diretas não podem informar o IP do cliente por headers
+	if !r.isTrusted(remote) {
+		return remote.String()
+	}
+
+	for _, header := range r.headers {
+		values := req.Header.Values(header)
+		if len(values) == 0 {
+			continue
+		}
+
+		var ip net.IP
+		switch http.CanonicalHeaderKey(header) {
+		case http.CanonicalHeaderKey(HeaderXForwardedFor):
+			ip = r.fromChain(splitList(values))
+		case http.CanonicalHeaderKey(HeaderForwarded):
+			ip = r.fromChain(forwardedFor(values))
+		default:
+			ip = parseIP(values[0])
+		}
+
+		if ip != nil {
+			return ip.String()
+		}
+	}
+
+	return remote.String()
+}
+
+// fromChain percorre a cadeia de proxies da direita para a esquerda e retorna
+// o primeiro IP que não é de um proxy confiável. Entradas inválidas
+// interrompem a busca, pois as anteriores não são confiáveis.
+func (r *ClientIPResolver) fromChain(chain []string) net.IP {
+	var last net.IP
+	for i := len(chain) - 1; i >= 0; i-- {
+		ip := parseIP(chain[i])
+		if ip == nil {
+			return last
+		}
+		if !r.isTrusted(ip) {
+			return ip
+		}
+		last = ip
+	}
+	return last
+}
+
+// isTrusted verifica se o IP pertence a um proxy confiável
+func (r *ClientIPResolver) isTrusted(ip net.IP) bool {
+	for _, network := range r.trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitList separa os valores de um header em lista separada por vírgulas
+func splitList(values []string) []string {
+	var items []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			items = append(items, strings.TrimSpace(item))
+		}
+	}
+	return items
+}
+
+// forwardedFor extrai os parâmetros for= do header Forwarded (RFC 7239),
+// ex: for=192.0.2.60;proto=http, for="[2001:db8::1]:4711"
+func forwardedFor(values []string) []string {
+	var items []string
+	for _, element := range splitList(values) {
+		for _, pair := range strings.Split(element, ";") {
+			name, value, found := strings.Cut(strings.TrimSpace(pair), "=")
+			if found && strings.EqualFold(name, "for") {
+				items = append(items, strings.Trim(value, `"`))
+			}
+		}
+	}
+	return items
+}
+
+// parseNetwork interpreta um CIDR ou um IP individual, tratado como uma rede
+// de um único endereço. Retorna nil para valores vazios.
+func parseNetwork(value string) (*net.IPNet, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+
+	if !strings.Contains(value, "/") {
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return nil, fmt.Errorf("rede inválida: %s", value)
+		}
+		if ip.To4() != nil {
+			value += "/32"
+		} else {
+			value += "/128"
+		}
+	}
+
+	_, network, err := net.ParseCIDR(value)
+	if err != nil {
+		return nil, fmt.Errorf("rede inválida: %s", value)
+	}
+
+	// Redes IPv4 mapeadas em IPv6, como ::ffff:10.0.0.0/104, são convertidas
+	// para IPv4, a forma usada para os IPs dos clientes
+	if ipv4 := network.IP.To4(); ipv4 != nil && len(network.Mask) == net.IPv6len {
+		ones, _ := network.Mask.Size()
+		network = &net.IPNet{IP: ipv4, Mask: net.CIDRMask(ones-96, 32)}
+	}
+	return network, nil
+}
+
+// parseIP interpreta um IP com ou sem porta, incluindo IPv6 entre colchetes
+func parseIP(value string) net.IP {
+	value = strings.TrimSpace(value)
+	if host, _, err := net.SplitHostPort(value); err == nil {
+		value = host
+	}
+	return net.ParseIP(strings.Trim(value, "[]"))
+}
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientIPResolver(t *testing.T) {
+	resolver, err := NewClientIPResolver(
+		[]string{"10.0.0.0/8", "2001:db8::1"},
+		[]string{HeaderXForwardedFor, HeaderForwarded, HeaderCFConnectingIP, HeaderXRealIP},
+	)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		expected   string
+	}{
+		{
+			name:       "Conexão direta ignora headers",
+			remoteAddr: "203.0.113.7:4321",
+			headers:    map[string]string{HeaderXForwardedFor: "1.2.3.4"},
+			expected:   "203.0.113.7",
+		},
+		{
+			name:       "Proxy confiável sem headers",
+			remoteAddr: "10.0.0.1:4321",
+			expected:   "10.0.0.1",
+		},
+		{
+			name:       "X-Forwarded-For ignora IPs forjados à esquerda",
+			remoteAddr: "10.0.0.1:4321",
+			headers:    map[string]string{HeaderXForwardedFor: "6.6.6.6, 198.51.100.2, 10.0.0.2"},
+			expected:   "198.51.100.2",
+		},
+		{
+			name:       "X-Forwarded-For só com proxies confiáveis",
+			remoteAddr: "10.0.0.1:4321",
+			headers:    map[string]string{HeaderXForwardedFor: "10.0.0.3, 10.0.0.2"},
+			expected:   "10.0.0.3",
+		},
+		{
+			name:       "Forwarded com IPv6 entre colchetes",
+			remoteAddr: "[2001:db8::1]:443",
+			headers:    map[string]string{HeaderForwarded: `for=192.0.2.60;proto=http, for="[2001:db8:cafe::17]:4711"`},
+			expected:   "2001:db8:cafe::17",
+		},
+		{
+			name:       "CF-Connecting-IP",
+			remoteAddr: "10.0.0.1",
+			headers:    map[string]string{HeaderCFConnectingIP: "198.51.100.9"},
+			expected:   "198.51.100.9",
+		},
+		{
+			name:       "Header inválido passa para o próximo",
+			remoteAddr: "10.0.0.1:4321",
+			headers:    map[string]string{HeaderXForwardedFor: "garbage", HeaderXRealIP: "198.51.100.4"},
+			expected:   "198.51.100.4",
+		},
+		{
+			name:       "RemoteAddr inválido",
+			remoteAddr: "invalid-ip",
+			headers:    map[string]string{HeaderXRealIP: "198.51.100.4"},
+			expected:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+
+			assert.Equal(t, tt.expected, resolver.ClientIP(req))
+		})
+	}
+}
+
+func TestNewClientIPResolver_InvalidProxy(t *testing.T) {
+	_, err := NewClientIPResolver([]string{"not-a-cidr"}, nil)
+	assert.Error(t, err)
+
+	resolver, err := NewClientIPResolver(nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultClientIPHeaders, resolver.headers)
+}
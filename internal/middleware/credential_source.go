@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultTokenHeader é o header lido quando nenhuma fonte de API key é configurada
+const DefaultTokenHeader = "API_KEY"
+
+// CredentialSource lê a API key de uma parte da requisição. Retorna uma
+// string vazia quando a requisição não traz a API key nessa fonte.
+type CredentialSource interface {
+	Credential(c *gin.Context) string
+}
+
+// CredentialSourceFunc adapta uma função para a interface CredentialSource
+type CredentialSourceFunc func(c *gin.Context) string
+
+// Credential chama a própria função
+func (f CredentialSourceFunc) Credential(c *gin.Context) string {
+	return f(c)
+}
+
+// HeaderCredential lê a API key de um header, ex: X-API-Key
+func HeaderCredential(name string) CredentialSource {
+	return CredentialSourceFunc(func(c *gin.Context) string {
+		return strings.TrimSpace(c.GetHeader(name))
+	})
+}
+
+// BearerCredential lê a API key do header Authorization: Bearer
+func BearerCredential() CredentialSource {
+	return CredentialSourceFunc(func(c *gin.Context) string {
+		scheme, token, found := strings.Cut(c.GetHeader("Authorization"), " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") {
+			return ""
+		}
+		return strings.TrimSpace(token)
+	})
+}
+
+// QueryCredential lê a API key de um parâmetro da query string, ex: ?api_key=
+func QueryCredential(name string) CredentialSource {
+	return CredentialSourceFunc(func(c *gin.Context) string {
+		return c.Query(name)
+	})
+}
+
+// CookieCredential lê a API key de um cookie
+func CookieCredential(name string) CredentialSource {
+	return CredentialSourceFunc(func(c *gin.Context) string {
+		value, err := c.Cookie(name)
+		if err != nil {
+			return ""
+		}
+		return value
+	})
+}
+
+// ParseCredentialSources converte uma lista de fontes em ordem de prioridade
+// no formato "header:X-API-Key,bearer,query:api_key,cookie:api_key"
+func ParseCredentialSources(spec string) ([]CredentialSource, error) {
+	var sources []CredentialSource
+
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		kind, name, _ := strings.Cut(entry, ":")
+		kind = strings.ToLower(kind)
+		name = strings.TrimSpace(name)
+		if kind == "bearer" {
+			sources = append(sources, BearerCredential())
+			continue
+		}
+		if name == "" {
+			return nil, fmt.Errorf("fonte de API key inválida: %q", entry)
+		}
+
+		switch kind {
+		case "header":
+			sources = append(sources, HeaderCredential(name))
+		case "query":
+			sources = append(sources, QueryCredential(name))
+		case "cookie":
+			sources = append(sources, CookieCredential(name))
+		default:
+			return nil, fmt.Errorf("fonte de API key inválida: %q", entry)
+		}
+	}
+
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("nenhuma fonte de API key configurada")
+	}
+
+	return sources, nil
+}
@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiterMiddleware_CredentialSources(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sources, err := ParseCredentialSources("header:X-API-Key, bearer, query:api_key, cookie:api_key")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name               string
+		prepare            func(req *http.Request)
+		expectedIdentifier string
+		expectedIsToken    bool
+	}{
+		{
+			name: "Header",
+			prepare: func(req *http.Request) {
+				req.Header.Set("X-API-Key", "from-header")
+				req.Header.Set("Authorization", "Bearer from-bearer")
+			},
+			expectedIdentifier: "from-header",
+			expectedIsToken:    true,
+		},
+		{
+			name: "Bearer",
+			prepare: func(req *http.Request) {
+				req.Header.Set("Authorization", "Bearer from-bearer")
+				req.URL.RawQuery = "api_key=from-query"
+			},
+			expectedIdentifier: "from-bearer",
+			expectedIsToken:    true,
+		},
+		{
+			name: "Query",
+			prepare: func(req *http.Request) {
+				req.Header.Set("Authorization", "Basic dXNlcjpwYXNz")
+				req.URL.RawQuery = "api_key=from-query"
+				req.AddCookie(&http.Cookie{Name: "api_key", Value: "from-cookie"})
+			},
+			expectedIdentifier: "from-query",
+			expectedIsToken:    true,
+		},
+		{
+			name: "Cookie",
+			prepare: func(req *http.Request) {
+				req.AddCookie(&http.Cookie{Name: "api_key", Value: "from-cookie"})
+			},
+			expectedIdentifier: "from-cookie",
+			expectedIsToken:    true,
+		},
+		{
+			name: "API_KEY is ignored when not configured",
+			prepare: func(req *http.Request) {
+				req.Header.Set("API_KEY", "legacy")
+			},
+			expectedIdentifier: "192.168.1.1",
+			expectedIsToken:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCase := &MockRecordingUseCase{}
+			router := gin.New()
+			router.Use(RateLimiter(useCase, WithCredentialSources(sources...)))
+			router.GET("/", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = "192.168.1.1:1234"
+			tt.prepare(req)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, tt.expectedIdentifier, useCase.identifier)
+			assert.Equal(t, tt.expectedIsToken, useCase.isToken)
+		})
+	}
+}
+
+func TestParseCredentialSources(t *testing.T) {
+	sources, err := ParseCredentialSources("HEADER:API_KEY,Bearer")
+	require.NoError(t, err)
+	assert.Len(t, sources, 2)
+
+	for _, spec := range []string{"", "header:", "query", "form:api_key"} {
+		_, err := ParseCredentialSources(spec)
+		assert.Error(t, err, spec)
+	}
+}
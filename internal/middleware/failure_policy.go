@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultFailureRetryAfter é o Retry-After padrão das respostas 503 enviadas
+// quando o armazenamento do rate limiter falha
+const DefaultFailureRetryAfter = 5 * time.Second
+
+// FailurePolicy define o tratamento das requisições quando o armazenamento
+// do rate limiter, como o Redis, falha ou excede o tempo limite
+type FailurePolicy string
+
+const (
+	// FailClosed rejeita a requisição com 503 e Retry-After
+	FailClosed FailurePolicy = "closed"
+	// FailOpen libera a requisição sem limitação e registra a falha no log
+	FailOpen FailurePolicy = "open"
+	// FailFallback limita as requisições em um repositório em memória enquanto
+	// o armazenamento está indisponível (ver strategy.FallbackRepository). As
+	// falhas que ainda chegam ao middleware, como as do registro de tokens,
+	// são tratadas como FailClosed.
+	FailFallback FailurePolicy = "fallback"
+)
+
+// ParseFailurePolicy converte uma string de configuração em um
+// FailurePolicy. Uma string vazia resulta em FailClosed.
+func ParseFailurePolicy(value string) (FailurePolicy, error) {
+	switch FailurePolicy(value) {
+	case "":
+		return FailClosed, nil
+	case FailClosed, FailOpen, FailFallback:
+		return FailurePolicy(value), nil
+	default:
+		return "", fmt.Errorf("política de falha inválida: %q (use closed, open ou fallback)", value)
+	}
+}
+
+// storageContext limita o tempo das operações de armazenamento da requisição
+func (m *RateLimiterMiddleware) storageContext(c *gin.Context) (context.Context, context.CancelFunc) {
+	if m.storageTimeout <= 0 {
+		return c.Request.Context(), func() {}
+	}
+	return context.WithTimeout(c.Request.Context(), m.storageTimeout)
+}
+
+// storageFailure trata uma falha do armazenamento de acordo com a política
+// de falhas. Retorna true quando a requisição deve seguir sem limitação.
+func (m *RateLimiterMiddleware) storageFailure(c *gin.Context, err error) bool {
+	if m.failurePolicy == FailOpen {
+		log.Printf("Falha no armazenamento do rate limiter, requisição liberada: %v", err)
+		return true
+	}
+
+	log.Printf("Falha no armazenamento do rate limiter, requisição rejeitada: %v", err)
+	c.Header(headerRetryAfter, strconv.FormatInt(ceilSeconds(m.failureRetryAfter), 10))
+	c.JSON(http.StatusServiceUnavailable, gin.H{"error": errStorageUnavailable})
+	c.Abort()
+	return false
+}
@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowUseCase simula um armazenamento que só responde quando o contexto expira
+type slowUseCase struct{}
+
+func (slowUseCase) IsAllowed(ctx context.Context, identifier string, isToken bool) (bool, error) {
+	<-ctx.Done()
+	return false, ctx.Err()
+}
+
+func TestRateLimiterMiddleware_FailurePolicy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	storageErr := &MockUseCase{err: errors.New("redis down")}
+
+	tests := []struct {
+		name               string
+		opts               []Option
+		expectedStatus     int
+		expectedRetryAfter string
+	}{
+		{
+			name:               "Fail closed by default",
+			expectedStatus:     http.StatusServiceUnavailable,
+			expectedRetryAfter: "5",
+		},
+		{
+			name:               "Fail closed with custom Retry-After",
+			opts:               []Option{WithFailurePolicy(FailClosed, 1500*time.Millisecond)},
+			expectedStatus:     http.StatusServiceUnavailable,
+			expectedRetryAfter: "2",
+		},
+		{
+			name:           "Fail open",
+			opts:           []Option{WithFailurePolicy(FailOpen, 0)},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(RateLimiter(storageErr, tt.opts...))
+			router.GET("/", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("API_KEY", "abc123")
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Equal(t, tt.expectedRetryAfter, rr.Header().Get("Retry-After"))
+			if tt.expectedStatus == http.StatusServiceUnavailable {
+				assert.JSONEq(t, `{"error":"`+errStorageUnavailable+`"}`, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestRateLimiterMiddleware_StorageTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(RateLimiter(slowUseCase{},
+		WithStorageTimeout(20*time.Millisecond),
+		WithFailurePolicy(FailOpen, 0),
+	))
+	router.GET("/", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("API_KEY", "abc123")
+	rr := httptest.NewRecorder()
+
+	start := time.Now()
+	router.ServeHTTP(rr, req)
+
+	// A requisição não espera além do tempo limite do armazenamento
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestRateLimiterMiddleware_FailOpenKeyValidator(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	validator := KeyValidatorFunc(func(ctx context.Context, key string) (bool, error) {
+		return false, errors.New("redis down")
+	})
+	useCase := &MockRecordingUseCase{}
+
+	router := gin.New()
+	router.Use(RateLimiter(useCase,
+		WithKeyValidator(validator, UnknownKeyReject),
+		WithFailurePolicy(FailOpen, 0),
+	))
+	router.GET("/", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("API_KEY", "abc123")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	// Sem o registro, a API key é aceita e limitada como token
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "abc123", useCase.identifier)
+	assert.True(t, useCase.isToken)
+}
+
+func TestParseFailurePolicy(t *testing.T) {
+	for value, expected := range map[string]FailurePolicy{
+		"":         FailClosed,
+		"closed":   FailClosed,
+		"open":     FailOpen,
+		"fallback": FailFallback,
+	} {
+		policy, err := ParseFailurePolicy(value)
+		require.NoError(t, err)
+		assert.Equal(t, expected, policy)
+	}
+
+	_, err := ParseFailurePolicy("ignore")
+	assert.Error(t, err)
+}
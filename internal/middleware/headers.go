@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JMKobayashi/Rate-Limiter-GO/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// headerLimit informa o número de requisições permitidas por janela
+	headerLimit = "X-RateLimit-Limit"
+	// headerRemaining informa as requisições ainda disponíveis
+	headerRemaining = "X-RateLimit-Remaining"
+	// headerReset informa, em segundos desde a época Unix, quando a cota é reiniciada
+	headerReset = "X-RateLimit-Reset"
+	// headerRetryAfter informa, em segundos, quando o cliente pode tentar novamente
+	headerRetryAfter = "Retry-After"
+	// headerRateLimit informa o estado de cada política (draft IETF RateLimit headers)
+	headerRateLimit = "RateLimit"
+	// headerRateLimitPolicy descreve cada política aplicada (draft IETF RateLimit headers)
+	headerRateLimitPolicy = "RateLimit-Policy"
+)
+
+// HeaderStyle define quais headers de rate limit são enviados nas respostas
+type HeaderStyle string
+
+const (
+	// LegacyHeaders envia os headers X-RateLimit-*
+	LegacyHeaders HeaderStyle = "legacy"
+	// IETFHeaders envia os headers RateLimit e RateLimit-Policy do draft IETF
+	IETFHeaders HeaderStyle = "ietf"
+	// BothHeaders envia os dois conjuntos de headers
+	BothHeaders HeaderStyle = "both"
+)
+
+// ParseHeaderStyle converte uma string de configuração em um HeaderStyle.
+// Uma string vazia resulta em LegacyHeaders.
+func ParseHeaderStyle(value string) (HeaderStyle, error) {
+	switch HeaderStyle(value) {
+	case "":
+		return LegacyHeaders, nil
+	case LegacyHeaders, IETFHeaders, BothHeaders:
+		return HeaderStyle(value), nil
+	default:
+		return "", fmt.Errorf("estilo de headers inválido: %s", value)
+	}
+}
+
+// setRateLimitHeaders escreve os headers de rate limit no estilo configurado.
+// Retry-After é enviado em respostas 429 em todos os estilos.
+func setRateLimitHeaders(c *gin.Context, decision *usecase.Decision, style HeaderStyle) {
+	if style == IETFHeaders || style == BothHeaders {
+		setIETFHeaders(c, decision)
+	}
+	if style != IETFHeaders {
+		setLegacyHeaders(c, decision)
+	}
+
+	if !decision.Allowed && decision.Limit > 0 {
+		c.Header(headerRetryAfter, strconv.FormatInt(max(1, ceilSeconds(decision.RetryAfter)), 10))
+	}
+}
+
+// setLegacyHeaders escreve os headers X-RateLimit-* a partir da decisão.
+// Decisões sem limite aplicado não geram headers.
+func setLegacyHeaders(c *gin.Context, decision *usecase.Decision) {
+	if decision.Limit <= 0 {
+		return
+	}
+
+	c.Header(headerLimit, strconv.FormatInt(decision.Limit, 10))
+	c.Header(headerRemaining, strconv.FormatInt(decision.Remaining, 10))
+	if !decision.ResetAt.IsZero() {
+		c.Header(headerReset, strconv.FormatInt(ceilSeconds(decision.ResetAt.Sub(time.Unix(0, 0))), 10))
+	}
+}
+
+// setIETFHeaders escreve os headers RateLimit e RateLimit-Policy como listas
+// de structured fields, com um item por política verificada:
+//
+//	RateLimit-Policy: "token";q=100;w=1, "monthly";q=1000000;w=2678400
+//	RateLimit: "token";r=99;t=1, "monthly";r=999999;t=86400
+func setIETFHeaders(c *gin.Context, decision *usecase.Decision) {
+	if len(decision.Policies) == 0 {
+		return
+	}
+
+	policies := make([]string, len(decision.Policies))
+	limits := make([]string, len(decision.Policies))
+	for i, policy := range decision.Policies {
+		policies[i] = fmt.Sprintf("%q;q=%d;w=%d", policy.Name, policy.Limit, ceilSeconds(policy.Window))
+		limits[i] = fmt.Sprintf("%q;r=%d;t=%d", policy.Name, policy.Remaining, max(0, ceilSeconds(time.Until(policy.ResetAt))))
+	}
+
+	c.Header(headerRateLimitPolicy, strings.Join(policies, ", "))
+	c.Header(headerRateLimit, strings.Join(limits, ", "))
+}
+
+// ceilSeconds arredonda a duração para cima em segundos inteiros, para que o
+// cliente não tente novamente antes do tempo
+func ceilSeconds(d time.Duration) int64 {
+	return int64((d + time.Second - 1) / time.Second)
+}
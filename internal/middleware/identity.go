@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrInvalidCredentials indica que a requisição trouxe credenciais que não
+// puderam ser validadas
+var ErrInvalidCredentials = errors.New("credenciais inválidas")
+
+// TokenIdentity é a identidade de token extraída da requisição
+type TokenIdentity struct {
+	// Token é o identificador limitado como token
+	Token string
+	// Limit é o limite próprio da identidade. Zero usa o limite configurado
+	Limit int
+}
+
+// IdentityExtractor obtém a identidade de token das credenciais da requisição.
+// Retorna nil, sem erro, quando a requisição não traz credenciais, e um erro
+// que envolve ErrInvalidCredentials quando elas são inválidas.
+type IdentityExtractor interface {
+	Extract(c *gin.Context) (*TokenIdentity, error)
+}
@@ -0,0 +1,169 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+)
+
+// IPAccess é o resultado da consulta de um IP nas listas de acesso
+type IPAccess int
+
+const (
+	// IPAccessDefault indica que o IP não está em nenhuma lista e passa pelo rate limiter
+	IPAccessDefault IPAccess = iota
+	// IPAccessAllowed indica que o IP está na lista de permissão e ignora o rate limiter
+	IPAccessAllowed
+	// IPAccessDenied indica que o IP está na lista de bloqueio e é rejeitado com 403
+	IPAccessDenied
+)
+
+// IPAccessList guarda as redes sempre permitidas e sempre bloqueadas. A lista
+// de bloqueio tem precedência quando um IP está nas duas. As listas podem ser
+// substituídas em tempo de execução com Update.
+type IPAccessList struct {
+	mu    sync.RWMutex
+	allow *prefixTrie
+	deny  *prefixTrie
+}
+
+// IPAccessListFile é o formato do arquivo JSON das listas de acesso
+type IPAccessListFile struct {
+	Allow []string `json:"allow"`
+	Deny  []string `json:"deny"`
+}
+
+// NewIPAccessList cria as listas de acesso a partir de CIDRs ou IPs individuais
+func NewIPAccessList(allow, deny []string) (*IPAccessList, error) {
+	list := &IPAccessList{}
+	if err := list.Update(allow, deny); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// LoadIPAccessListFile lê as listas de acesso de um arquivo JSON no formato
+// {"allow": ["10.0.0.0/8"], "deny": ["203.0.113.0/24"]}
+func LoadIPAccessListFile(path string) (*IPAccessListFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler listas de acesso: %w", err)
+	}
+
+	var file IPAccessListFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("erro ao decodificar listas de acesso: %w", err)
+	}
+
+	return &file, nil
+}
+
+// Update substitui as duas listas. Se alguma rede for inválida, as listas
+// atuais são mantidas.
+func (l *IPAccessList) Update(allow, deny []string) error {
+	allowTrie, err := newPrefixTrie(allow)
+	if err != nil {
+		return err
+	}
+	denyTrie, err := newPrefixTrie(deny)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.allow = allowTrie
+	l.deny = denyTrie
+	return nil
+}
+
+// Lookup consulta o IP nas listas de acesso
+func (l *IPAccessList) Lookup(ip net.IP) IPAccess {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if l.deny.contains(ip) {
+		return IPAccessDenied
+	}
+	if l.allow.contains(ip) {
+		return IPAccessAllowed
+	}
+	return IPAccessDefault
+}
+
+// prefixTrie é uma árvore binária de prefixos de rede, com uma raiz para
+// IPv4 e outra para IPv6. A consulta percorre no máximo um nó por bit do IP,
+// independente do número de redes.
+type prefixTrie struct {
+	ipv4 trieNode
+	ipv6 trieNode
+}
+
+type trieNode struct {
+	children [2]*trieNode
+	terminal bool
+}
+
+// newPrefixTrie cria a árvore com as redes informadas
+func newPrefixTrie(networks []string) (*prefixTrie, error) {
+	trie := &prefixTrie{}
+	for _, value := range networks {
+		network, err := parseNetwork(value)
+		if err != nil {
+			return nil, err
+		}
+		if network == nil {
+			continue
+		}
+		trie.insert(network)
+	}
+	return trie, nil
+}
+
+// root retorna a raiz da família do IP e o IP na forma de 4 ou 16 bytes
+func (t *prefixTrie) root(ip net.IP) (*trieNode, net.IP) {
+	if ipv4 := ip.To4(); ipv4 != nil {
+		return &t.ipv4, ipv4
+	}
+	return &t.ipv6, ip.To16()
+}
+
+// insert adiciona a rede à árvore
+func (t *prefixTrie) insert(network *net.IPNet) {
+	node, ip := t.root(network.IP)
+	ones, _ := network.Mask.Size()
+	for i := 0; i < ones; i++ {
+		bit := bitAt(ip, i)
+		if node.children[bit] == nil {
+			node.children[bit] = &trieNode{}
+		}
+		node = node.children[bit]
+	}
+	node.terminal = true
+}
+
+// contains verifica se o IP pertence a alguma rede da árvore
+func (t *prefixTrie) contains(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+
+	node, ip := t.root(ip)
+	for i := 0; node != nil; i++ {
+		if node.terminal {
+			return true
+		}
+		if i == len(ip)*8 {
+			return false
+		}
+		node = node.children[bitAt(ip, i)]
+	}
+	return false
+}
+
+// bitAt retorna o i-ésimo bit do IP, a partir do mais significativo
+func bitAt(ip net.IP, i int) int {
+	return int(ip[i/8]>>(7-i%8)) & 1
+}
@@ -0,0 +1,132 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIPAccessList_Lookup(t *testing.T) {
+	list, err := NewIPAccessList(
+		[]string{"10.0.0.0/8", "192.168.1.10", "2001:db8::/32"},
+		[]string{"10.6.6.0/24", "203.0.113.0/24", "2001:db8:bad::/48"},
+	)
+	require.NoError(t, err)
+
+	tests := []struct {
+		ip       string
+		expected IPAccess
+	}{
+		{"10.1.2.3", IPAccessAllowed},
+		{"192.168.1.10", IPAccessAllowed},
+		{"192.168.1.11", IPAccessDefault},
+		{"203.0.113.50", IPAccessDenied},
+		// A lista de bloqueio tem precedência
+		{"10.6.6.6", IPAccessDenied},
+		{"::ffff:10.1.2.3", IPAccessAllowed},
+		{"2001:db8:1::1", IPAccessAllowed},
+		{"2001:db8:bad::1", IPAccessDenied},
+		{"2001:db9::1", IPAccessDefault},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			assert.Equal(t, tt.expected, list.Lookup(net.ParseIP(tt.ip)))
+		})
+	}
+
+	assert.Equal(t, IPAccessDefault, list.Lookup(nil))
+}
+
+func TestIPAccessList_Update(t *testing.T) {
+	list, err := NewIPAccessList(nil, []string{"203.0.113.0/24"})
+	require.NoError(t, err)
+	assert.Equal(t, IPAccessDenied, list.Lookup(net.ParseIP("203.0.113.1")))
+
+	require.NoError(t, list.Update([]string{"203.0.113.0/24"}, nil))
+	assert.Equal(t, IPAccessAllowed, list.Lookup(net.ParseIP("203.0.113.1")))
+
+	// Redes inválidas mantêm as listas atuais
+	assert.Error(t, list.Update(nil, []string{"not-a-cidr"}))
+	assert.Equal(t, IPAccessAllowed, list.Lookup(net.ParseIP("203.0.113.1")))
+
+	// Uma rede /0 abrange todos os IPs da família
+	require.NoError(t, list.Update(nil, []string{"0.0.0.0/0"}))
+	assert.Equal(t, IPAccessDenied, list.Lookup(net.ParseIP("198.51.100.1")))
+	assert.Equal(t, IPAccessDefault, list.Lookup(net.ParseIP("2001:db8::1")))
+}
+
+func TestIPAccessList_IPv4MappedNetworks(t *testing.T) {
+	// Redes IPv4 mapeadas em IPv6 valem para os IPv4 correspondentes
+	list, err := NewIPAccessList([]string{"::ffff:10.0.0.0/104"}, []string{"::ffff:0:0/96"})
+	require.NoError(t, err)
+	assert.Equal(t, IPAccessDenied, list.Lookup(net.ParseIP("10.1.2.3")))
+	assert.Equal(t, IPAccessDenied, list.Lookup(net.ParseIP("198.51.100.1")))
+	assert.Equal(t, IPAccessDefault, list.Lookup(net.ParseIP("2001:db8::1")))
+
+	require.NoError(t, list.Update([]string{"::ffff:10.0.0.0/104"}, nil))
+	assert.Equal(t, IPAccessAllowed, list.Lookup(net.ParseIP("10.1.2.3")))
+	assert.Equal(t, IPAccessAllowed, list.Lookup(net.ParseIP("::ffff:10.1.2.3")))
+	assert.Equal(t, IPAccessDefault, list.Lookup(net.ParseIP("11.0.0.1")))
+}
+
+func TestLoadIPAccessListFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ip_access.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"allow":["10.0.0.0/8"],"deny":["203.0.113.0/24"]}`), 0o600))
+
+	file, err := LoadIPAccessListFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.0/8"}, file.Allow)
+	assert.Equal(t, []string{"203.0.113.0/24"}, file.Deny)
+
+	_, err = LoadIPAccessListFile(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestRateLimiterMiddleware_IPAccessList(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	list, err := NewIPAccessList([]string{"10.0.0.0/8"}, []string{"203.0.113.0/24"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		remoteAddr     string
+		expectedStatus int
+		expectedCalled bool
+	}{
+		{"Allowed network bypasses limiter", "10.1.2.3:1234", http.StatusOK, false},
+		{"Denied network is rejected", "203.0.113.9:1234", http.StatusForbidden, false},
+		{"Other networks are limited", "198.51.100.1:1234", http.StatusOK, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCase := &MockRecordingUseCase{}
+			router := gin.New()
+			router.Use(RateLimiter(useCase, WithIPAccessList(list)))
+			router.GET("/", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("API_KEY", "test-token")
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Equal(t, tt.expectedCalled, useCase.identifier != "")
+			if tt.expectedStatus == http.StatusForbidden {
+				assert.JSONEq(t, `{"error":"`+errAccessDenied+`"}`, rr.Body.String())
+			}
+		})
+	}
+}
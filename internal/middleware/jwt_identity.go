@@ -0,0 +1,356 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// JWTAlgorithmHS256 é o HMAC com SHA-256, verificado com um segredo compartilhado
+	JWTAlgorithmHS256 = "HS256"
+	// JWTAlgorithmRS256 é o RSA PKCS#1 v1.5 com SHA-256, verificado com uma chave pública
+	JWTAlgorithmRS256 = "RS256"
+)
+
+// DefaultJWTLeeway é a tolerância de relógio na validação de exp e nbf
+const DefaultJWTLeeway = 30 * time.Second
+
+// JWTTokenPrefix precede o claim de identidade no token limitado, para que as
+// identidades por JWT não compartilhem contadores nem políticas do registro
+// com API keys de mesmo valor
+const JWTTokenPrefix = "jwt:"
+
+// jwtKey é uma chave de verificação de JWT
+type jwtKey struct {
+	id        string
+	algorithm string
+	secret    []byte
+	publicKey *rsa.PublicKey
+}
+
+// JWTKeySet guarda as chaves usadas para verificar os JWTs. Cada chave só
+// verifica tokens do seu próprio algoritmo.
+type JWTKeySet struct {
+	keys []jwtKey
+}
+
+// LoadHMACSecretFile carrega um segredo HS256 de um arquivo
+func LoadHMACSecretFile(path string) (*JWTKeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler segredo do JWT: %w", err)
+	}
+
+	secret := bytes.TrimSpace(data)
+	if len(secret) == 0 {
+		return nil, errors.New("segredo do JWT vazio")
+	}
+
+	return &JWTKeySet{keys: []jwtKey{{algorithm: JWTAlgorithmHS256, secret: secret}}}, nil
+}
+
+// LoadRSAPublicKeyFile carrega uma chave pública RS256 de um arquivo PEM,
+// no formato PKIX, PKCS#1 ou certificado X.509
+func LoadRSAPublicKeyFile(path string) (*JWTKeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler chave pública do JWT: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("chave pública do JWT não está no formato PEM")
+	}
+
+	var publicKey any
+	switch block.Type {
+	case "RSA PUBLIC KEY":
+		publicKey, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		var certificate *x509.Certificate
+		certificate, err = x509.ParseCertificate(block.Bytes)
+		if err == nil {
+			publicKey = certificate.PublicKey
+		}
+	default:
+		publicKey, err = x509.ParsePKIXPublicKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao decodificar chave pública do JWT: %w", err)
+	}
+
+	rsaKey, ok := publicKey.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("chave pública do JWT não é RSA")
+	}
+
+	return &JWTKeySet{keys: []jwtKey{{algorithm: JWTAlgorithmRS256, publicKey: rsaKey}}}, nil
+}
+
+// jwk é uma chave de um arquivo JWKS (RFC 7517)
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	K   string `json:"k"`
+}
+
+// LoadJWKSFile carrega as chaves de um arquivo JWKS. São aceitas chaves RSA
+// (RS256) e simétricas (oct, HS256); chaves de outros tipos ou usos são ignoradas.
+func LoadJWKSFile(path string) (*JWTKeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler JWKS: %w", err)
+	}
+
+	var file struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("erro ao decodificar JWKS: %w", err)
+	}
+
+	keySet := &JWTKeySet{}
+	for _, key := range file.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+
+		switch {
+		case key.Kty == "RSA" && (key.Alg == "" || key.Alg == JWTAlgorithmRS256):
+			n, errN := base64.RawURLEncoding.DecodeString(key.N)
+			e, errE := base64.RawURLEncoding.DecodeString(key.E)
+			if errN != nil || errE != nil || len(n) == 0 || len(e) == 0 {
+				return nil, fmt.Errorf("chave RSA inválida no JWKS: %q", key.Kid)
+			}
+			keySet.keys = append(keySet.keys, jwtKey{
+				id:        key.Kid,
+				algorithm: JWTAlgorithmRS256,
+				publicKey: &rsa.PublicKey{
+					N: new(big.Int).SetBytes(n),
+					E: int(new(big.Int).SetBytes(e).Int64()),
+				},
+			})
+		case key.Kty == "oct" && (key.Alg == "" || key.Alg == JWTAlgorithmHS256):
+			secret, err := base64.RawURLEncoding.DecodeString(key.K)
+			if err != nil || len(secret) == 0 {
+				return nil, fmt.Errorf("chave simétrica inválida no JWKS: %q", key.Kid)
+			}
+			keySet.keys = append(keySet.keys, jwtKey{id: key.Kid, algorithm: JWTAlgorithmHS256, secret: secret})
+		}
+	}
+
+	if len(keySet.keys) == 0 {
+		return nil, errors.New("JWKS sem chaves RS256 ou HS256")
+	}
+
+	return keySet, nil
+}
+
+// verify verifica a assinatura com a chave do kid e do algoritmo informados.
+// Sem kid, qualquer chave do algoritmo é aceita.
+func (s *JWTKeySet) verify(algorithm, kid string, signed, signature []byte) bool {
+	for _, key := range s.keys {
+		if key.algorithm != algorithm || (kid != "" && key.id != "" && key.id != kid) {
+			continue
+		}
+
+		switch algorithm {
+		case JWTAlgorithmHS256:
+			mac := hmac.New(sha256.New, key.secret)
+			mac.Write(signed)
+			if hmac.Equal(mac.Sum(nil), signature) {
+				return true
+			}
+		case JWTAlgorithmRS256:
+			digest := sha256.Sum256(signed)
+			if rsa.VerifyPKCS1v15(key.publicKey, crypto.SHA256, digest[:], signature) == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// JWTIdentity extrai a identidade de token de um JWT enviado no header
+// Authorization: Bearer, depois de verificar a assinatura e a validade
+type JWTIdentity struct {
+	keySet     *JWTKeySet
+	claim      string
+	limitClaim string
+	leeway     time.Duration
+	now        func() time.Time
+}
+
+// JWTOption configura parâmetros opcionais da JWTIdentity
+type JWTOption func(*JWTIdentity)
+
+// WithJWTLimitClaim lê o limite próprio da identidade de um claim numérico
+func WithJWTLimitClaim(claim string) JWTOption {
+	return func(j *JWTIdentity) {
+		j.limitClaim = claim
+	}
+}
+
+// WithJWTLeeway define a tolerância de relógio na validação de exp e nbf
+func WithJWTLeeway(leeway time.Duration) JWTOption {
+	return func(j *JWTIdentity) {
+		j.leeway = leeway
+	}
+}
+
+// WithJWTClock define a função usada para obter o horário atual
+func WithJWTClock(now func() time.Time) JWTOption {
+	return func(j *JWTIdentity) {
+		if now != nil {
+			j.now = now
+		}
+	}
+}
+
+// NewJWTIdentity cria o extrator de identidade por JWT. claim é o claim usado
+// como identificador, ex: sub, client_id ou tenant.
+func NewJWTIdentity(keySet *JWTKeySet, claim string, opts ...JWTOption) *JWTIdentity {
+	j := &JWTIdentity{
+		keySet: keySet,
+		claim:  claim,
+		leeway: DefaultJWTLeeway,
+		now:    time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(j)
+	}
+
+	return j
+}
+
+// Extract verifica o JWT do header Authorization. Requisições sem bearer token
+// não têm identidade de token.
+func (j *JWTIdentity) Extract(c *gin.Context) (*TokenIdentity, error) {
+	scheme, token, found := strings.Cut(c.GetHeader("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return nil, nil
+	}
+
+	claims, err := j.parse(strings.TrimSpace(token))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+
+	subject := claimString(claims[j.claim])
+	if subject == "" {
+		return nil, fmt.Errorf("%w: claim %s ausente", ErrInvalidCredentials, j.claim)
+	}
+	identity := &TokenIdentity{Token: JWTTokenPrefix + subject}
+
+	if j.limitClaim != "" {
+		if value, exists := claims[j.limitClaim]; exists {
+			limit, err := strconv.Atoi(claimString(value))
+			if err != nil || limit < 0 {
+				return nil, fmt.Errorf("%w: claim %s inválido", ErrInvalidCredentials, j.limitClaim)
+			}
+			identity.Limit = limit
+		}
+	}
+
+	return identity, nil
+}
+
+// parse verifica a assinatura, exp e nbf do JWT e retorna seus claims
+func (j *JWTIdentity) parse(token string) (map[string]any, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("JWT mal formado")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New("assinatura do JWT mal formada")
+	}
+	if !j.keySet.verify(header.Alg, header.Kid, []byte(parts[0]+"."+parts[1]), signature) {
+		return nil, errors.New("assinatura do JWT inválida")
+	}
+
+	var claims map[string]any
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+
+	now := j.now()
+	if exp, ok := claimTime(claims["exp"]); ok && !now.Before(exp.Add(j.leeway)) {
+		return nil, errors.New("JWT expirado")
+	}
+	if nbf, ok := claimTime(claims["nbf"]); ok && now.Add(j.leeway).Before(nbf) {
+		return nil, errors.New("JWT ainda não é válido")
+	}
+
+	return claims, nil
+}
+
+// decodeSegment decodifica um segmento base64url com JSON do JWT
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return errors.New("JWT mal formado")
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(v); err != nil {
+		return errors.New("JWT mal formado")
+	}
+	return nil
+}
+
+// claimString converte um claim textual ou numérico em texto
+func claimString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	default:
+		return ""
+	}
+}
+
+// claimTime converte um claim NumericDate (segundos desde a época) em horário
+func claimTime(value any) (time.Time, bool) {
+	number, ok := value.(json.Number)
+	if !ok {
+		return time.Time{}, false
+	}
+	seconds, err := number.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(0, int64(seconds*float64(time.Second))), true
+}
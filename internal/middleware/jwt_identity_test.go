@@ -0,0 +1,309 @@
+package middleware
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/JMKobayashi/Rate-Limiter-GO/internal/entity"
+	"github.com/JMKobayashi/Rate-Limiter-GO/internal/limiter/strategy"
+	"github.com/JMKobayashi/Rate-Limiter-GO/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// signJWT monta um JWT assinado com HS256 (secret) ou RS256 (privateKey)
+func signJWT(t *testing.T, header, claims map[string]any, secret []byte, privateKey *rsa.PrivateKey) string {
+	t.Helper()
+
+	encode := func(v any) string {
+		data, err := json.Marshal(v)
+		require.NoError(t, err)
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	signed := encode(header) + "." + encode(claims)
+
+	var signature []byte
+	if privateKey != nil {
+		digest := sha256.Sum256([]byte(signed))
+		var err error
+		signature, err = rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+	} else {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// extract executa o extrator sobre uma requisição com o header Authorization
+func extract(t *testing.T, identity *JWTIdentity, authorization string) (*TokenIdentity, error) {
+	t.Helper()
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/", nil)
+	if authorization != "" {
+		c.Request.Header.Set("Authorization", authorization)
+	}
+	return identity.Extract(c)
+}
+
+func writeFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func TestJWTIdentity_HS256(t *testing.T) {
+	secret := []byte("super-secret")
+	keySet, err := LoadHMACSecretFile(writeFile(t, "secret", append(secret, '\n')))
+	require.NoError(t, err)
+
+	now := time.Unix(1700000000, 0)
+	identity := NewJWTIdentity(keySet, "client_id",
+		WithJWTLimitClaim("rate_limit"),
+		WithJWTLeeway(0),
+		WithJWTClock(func() time.Time { return now }),
+	)
+	header := map[string]any{"alg": "HS256", "typ": "JWT"}
+
+	tests := []struct {
+		name     string
+		token    string
+		expected *TokenIdentity
+		wantErr  bool
+	}{
+		{
+			name:     "Token válido",
+			token:    signJWT(t, header, map[string]any{"client_id": "acme", "exp": now.Unix() + 60}, secret, nil),
+			expected: &TokenIdentity{Token: "jwt:acme"},
+		},
+		{
+			name:     "Limite no claim",
+			token:    signJWT(t, header, map[string]any{"client_id": "acme", "rate_limit": 500}, secret, nil),
+			expected: &TokenIdentity{Token: "jwt:acme", Limit: 500},
+		},
+		{
+			name:    "Segredo errado",
+			token:   signJWT(t, header, map[string]any{"client_id": "acme"}, []byte("other"), nil),
+			wantErr: true,
+		},
+		{
+			name:    "Algoritmo none",
+			token:   signJWT(t, map[string]any{"alg": "none"}, map[string]any{"client_id": "acme"}, secret, nil),
+			wantErr: true,
+		},
+		{
+			name:    "Token expirado",
+			token:   signJWT(t, header, map[string]any{"client_id": "acme", "exp": now.Unix()}, secret, nil),
+			wantErr: true,
+		},
+		{
+			name:    "Token ainda não válido",
+			token:   signJWT(t, header, map[string]any{"client_id": "acme", "nbf": now.Unix() + 60}, secret, nil),
+			wantErr: true,
+		},
+		{
+			name:    "Claim ausente",
+			token:   signJWT(t, header, map[string]any{"sub": "acme"}, secret, nil),
+			wantErr: true,
+		},
+		{
+			name:    "Limite inválido",
+			token:   signJWT(t, header, map[string]any{"client_id": "acme", "rate_limit": "many"}, secret, nil),
+			wantErr: true,
+		},
+		{
+			name:    "Token mal formado",
+			token:   "not-a-jwt",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := extract(t, identity, "Bearer "+tt.token)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidCredentials)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+
+	// Requisições sem bearer token não têm identidade
+	result, err := extract(t, identity, "")
+	require.NoError(t, err)
+	assert.Nil(t, result)
+
+	result, err = extract(t, identity, "Basic dXNlcjpwYXNz")
+	require.NoError(t, err)
+	assert.Nil(t, result)
+}
+
+func TestJWTIdentity_RS256(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	publicDER, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	require.NoError(t, err)
+	pemKeySet, err := LoadRSAPublicKeyFile(writeFile(t, "public.pem",
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})))
+	require.NoError(t, err)
+
+	jwks, err := json.Marshal(map[string]any{"keys": []map[string]any{
+		{
+			"kty": "RSA", "kid": "other", "use": "sig",
+			"n": base64.RawURLEncoding.EncodeToString(otherKey.N.Bytes()),
+			"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(otherKey.E)).Bytes()),
+		},
+		{
+			"kty": "RSA", "kid": "main", "alg": "RS256",
+			"n": base64.RawURLEncoding.EncodeToString(privateKey.N.Bytes()),
+			"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(privateKey.E)).Bytes()),
+		},
+		{"kty": "EC", "kid": "ignored"},
+	}})
+	require.NoError(t, err)
+	jwksKeySet, err := LoadJWKSFile(writeFile(t, "jwks.json", jwks))
+	require.NoError(t, err)
+
+	claims := map[string]any{"sub": "user-1"}
+	for name, keySet := range map[string]*JWTKeySet{"PEM": pemKeySet, "JWKS": jwksKeySet} {
+		t.Run(name, func(t *testing.T) {
+			identity := NewJWTIdentity(keySet, "sub")
+
+			token := signJWT(t, map[string]any{"alg": "RS256", "kid": "main"}, claims, nil, privateKey)
+			result, err := extract(t, identity, "Bearer "+token)
+			require.NoError(t, err)
+			assert.Equal(t, &TokenIdentity{Token: "jwt:user-1"}, result)
+
+			// Assinado por outra chave
+			token = signJWT(t, map[string]any{"alg": "RS256", "kid": "main"}, claims, nil, otherKey)
+			_, err = extract(t, identity, "Bearer "+token)
+			assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+			// Uma chave RSA não verifica tokens HS256 assinados com a chave pública
+			token = signJWT(t, map[string]any{"alg": "HS256"}, claims, publicDER, nil)
+			_, err = extract(t, identity, "Bearer "+token)
+			assert.ErrorIs(t, err, ErrInvalidCredentials)
+		})
+	}
+
+	_, err = LoadJWKSFile(writeFile(t, "empty.json", []byte(`{"keys":[]}`)))
+	assert.Error(t, err)
+}
+
+func TestRateLimiterMiddleware_JWTIdentity(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	secret := []byte("super-secret")
+	keySet, err := LoadHMACSecretFile(writeFile(t, "secret", secret))
+	require.NoError(t, err)
+
+	repo, err := strategy.NewRepository(strategy.MemoryRepository, nil)
+	require.NoError(t, err)
+	useCase := usecase.NewRateLimiterUseCase(repo, 10, 2, 0, 0, true, true,
+		usecase.WithWindows(time.Minute, time.Minute),
+		usecase.WithAlgorithms(strategy.SlidingWindow, strategy.SlidingWindow),
+		usecase.WithTokenRegistry(usecase.ContextTokenRegistry()),
+	)
+
+	router := gin.New()
+	router.Use(RateLimiter(useCase, WithIdentityExtractor(
+		NewJWTIdentity(keySet, "sub", WithJWTLimitClaim("rate_limit")),
+	)))
+	router.GET("/", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	send := func(authorization string) int {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = "192.168.1.1:1234"
+		req.Header.Set("Authorization", authorization)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr.Code
+	}
+	header := map[string]any{"alg": "HS256"}
+
+	// Sem o claim de limite, vale o limite global por token
+	basic := "Bearer " + signJWT(t, header, map[string]any{"sub": "basic"}, secret, nil)
+	assert.Equal(t, http.StatusOK, send(basic))
+	assert.Equal(t, http.StatusOK, send(basic))
+	assert.Equal(t, http.StatusTooManyRequests, send(basic))
+
+	// O claim de limite substitui o limite global
+	premium := "Bearer " + signJWT(t, header, map[string]any{"sub": "premium", "rate_limit": 4}, secret, nil)
+	for i := 0; i < 4; i++ {
+		assert.Equal(t, http.StatusOK, send(premium))
+	}
+	assert.Equal(t, http.StatusTooManyRequests, send(premium))
+
+	assert.Equal(t, http.StatusUnauthorized, send("Bearer invalid.jwt.token"))
+}
+
+func TestRateLimiterMiddleware_JWTIdentityCollision(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	secret := []byte("super-secret")
+	keySet, err := LoadHMACSecretFile(writeFile(t, "secret", secret))
+	require.NoError(t, err)
+
+	registry, err := strategy.NewMemoryTokenRegistry(entity.TokenPolicy{Token: "premium", Plan: "premium", Limit: 5})
+	require.NoError(t, err)
+	repo, err := strategy.NewRepository(strategy.MemoryRepository, nil)
+	require.NoError(t, err)
+	useCase := usecase.NewRateLimiterUseCase(repo, 10, 2, 0, 0, true, true,
+		usecase.WithWindows(time.Minute, time.Minute),
+		usecase.WithTokenRegistry(registry),
+	)
+
+	router := gin.New()
+	router.Use(RateLimiter(useCase, WithIdentityExtractor(NewJWTIdentity(keySet, "sub"))))
+	router.GET("/", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	send := func(header, value string) int {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = "192.168.1.1:1234"
+		req.Header.Set(header, value)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr.Code
+	}
+	jwt := "Bearer " + signJWT(t, map[string]any{"alg": "HS256"}, map[string]any{"sub": "premium"}, secret, nil)
+
+	// Um subject igual a uma API key registrada não herda o plano dela
+	assert.Equal(t, http.StatusOK, send("Authorization", jwt))
+	assert.Equal(t, http.StatusOK, send("Authorization", jwt))
+	assert.Equal(t, http.StatusTooManyRequests, send("Authorization", jwt))
+
+	// Nem consome o contador da API key
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, http.StatusOK, send("API_KEY", "premium"))
+	}
+	assert.Equal(t, http.StatusTooManyRequests, send("API_KEY", "premium"))
+}
@@ -0,0 +1,135 @@
+package middleware
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// keySeparator separa as dimensões de uma chave composta
+const keySeparator = "|"
+
+// Identity é a identificação da requisição feita pelo middleware
+type Identity struct {
+	// Identifier é a API key aceita ou, sem ela, o IP do cliente
+	Identifier string
+	// IsToken indica se Identifier é uma API key
+	IsToken bool
+	// ClientIP é o IP do cliente, mesmo quando há uma API key
+	ClientIP string
+}
+
+// KeyFunc compõe as dimensões da chave limitada a partir da requisição. A
+// chave sempre começa pela identidade base (API key ou IP), que continua
+// definindo o tipo do limite, a política do token e a agregação por rede; o
+// resultado da KeyFunc é anexado a ela, exceto quando é a própria identidade.
+type KeyFunc interface {
+	Key(c *gin.Context, identity Identity) string
+}
+
+// KeyFuncFunc adapta uma função para a interface KeyFunc
+type KeyFuncFunc func(c *gin.Context, identity Identity) string
+
+// Key chama a própria função
+func (f KeyFuncFunc) Key(c *gin.Context, identity Identity) string {
+	return f(c, identity)
+}
+
+// KeyByToken usa a API key ou, sem ela, o IP do cliente. É o identificador
+// padrão do middleware.
+func KeyByToken() KeyFunc {
+	return KeyFuncFunc(func(c *gin.Context, identity Identity) string {
+		return identity.Identifier
+	})
+}
+
+// KeyByIP usa o IP do cliente, mesmo quando há uma API key
+func KeyByIP() KeyFunc {
+	return KeyFuncFunc(func(c *gin.Context, identity Identity) string {
+		return identity.ClientIP
+	})
+}
+
+// KeyByRoute usa o método e o padrão da rota, ex: "GET /users/:id"
+func KeyByRoute() KeyFunc {
+	return KeyFuncFunc(func(c *gin.Context, identity Identity) string {
+		return routeKeys(c)[0]
+	})
+}
+
+// KeyByHeader usa o valor de um header da requisição, ex: X-Tenant-ID. Sem o
+// header, usa a identidade base, para que requisições sem ele não dividam a
+// mesma chave.
+func KeyByHeader(name string) KeyFunc {
+	return KeyFuncFunc(func(c *gin.Context, identity Identity) string {
+		if value := c.GetHeader(name); value != "" {
+			return value
+		}
+		return identity.Identifier
+	})
+}
+
+// KeyByUserAgent usa o header User-Agent
+func KeyByUserAgent() KeyFunc {
+	return KeyByHeader("User-Agent")
+}
+
+// CompositeKey combina várias dimensões em uma única chave, ex: KeyByRoute e
+// KeyByUserAgent geram "GET /search|curl/8.0". As dimensões iguais à
+// identidade base são omitidas, pois a chave já começa por ela.
+func CompositeKey(parts ...KeyFunc) KeyFunc {
+	return KeyFuncFunc(func(c *gin.Context, identity Identity) string {
+		values := make([]string, 0, len(parts))
+		for _, part := range parts {
+			if value := part.Key(c, identity); value != identity.Identifier {
+				values = append(values, value)
+			}
+		}
+		if len(values) == 0 {
+			return identity.Identifier
+		}
+		return strings.Join(values, keySeparator)
+	})
+}
+
+// ParseKeyFunc converte uma lista de dimensões no formato
+// "token,route" ou "ip,user_agent,header:X-Tenant-ID" em uma KeyFunc
+func ParseKeyFunc(spec string) (KeyFunc, error) {
+	var parts []KeyFunc
+
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, header, _ := strings.Cut(entry, ":")
+		switch strings.ToLower(name) {
+		case "token":
+			parts = append(parts, KeyByToken())
+		case "ip":
+			parts = append(parts, KeyByIP())
+		case "route":
+			parts = append(parts, KeyByRoute())
+		case "user_agent":
+			parts = append(parts, KeyByUserAgent())
+		case "header":
+			if header = strings.TrimSpace(header); header == "" {
+				return nil, fmt.Errorf("dimensão da chave inválida: %q", entry)
+			}
+			parts = append(parts, KeyByHeader(header))
+		default:
+			return nil, fmt.Errorf("dimensão da chave inválida: %q", entry)
+		}
+	}
+
+	switch len(parts) {
+	case 0:
+		return KeyByToken(), nil
+	case 1:
+		return parts[0], nil
+	default:
+		return CompositeKey(parts...), nil
+	}
+}
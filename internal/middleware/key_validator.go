@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/JMKobayashi/Rate-Limiter-GO/internal/repository"
+)
+
+// AnonymousToken é o identificador compartilhado pelas API keys desconhecidas
+// quando a política UnknownKeyAnonymous é usada
+const AnonymousToken = "anonymous-token"
+
+// KeyValidator verifica se uma API key é conhecida
+type KeyValidator interface {
+	ValidateKey(ctx context.Context, key string) (bool, error)
+}
+
+// KeyValidatorFunc adapta uma função para a interface KeyValidator
+type KeyValidatorFunc func(ctx context.Context, key string) (bool, error)
+
+// ValidateKey chama a própria função
+func (f KeyValidatorFunc) ValidateKey(ctx context.Context, key string) (bool, error) {
+	return f(ctx, key)
+}
+
+// RegistryKeyValidator considera conhecidas as API keys presentes no registro de tokens
+func RegistryKeyValidator(registry repository.TokenRegistry) KeyValidator {
+	return KeyValidatorFunc(func(ctx context.Context, key string) (bool, error) {
+		policy, err := registry.GetTokenPolicy(ctx, key)
+		if err != nil {
+			return false, err
+		}
+		return policy != nil, nil
+	})
+}
+
+// UnknownKeyPolicy define o tratamento de requisições com API key desconhecida
+type UnknownKeyPolicy string
+
+const (
+	// UnknownKeyAllow trata qualquer API key como um token válido
+	UnknownKeyAllow UnknownKeyPolicy = "allow"
+	// UnknownKeyReject rejeita a requisição com 401
+	UnknownKeyReject UnknownKeyPolicy = "reject"
+	// UnknownKeyIP ignora a API key e limita a requisição pelo IP
+	UnknownKeyIP UnknownKeyPolicy = "ip"
+	// UnknownKeyAnonymous limita todas as API keys desconhecidas em um único
+	// token compartilhado, AnonymousToken
+	UnknownKeyAnonymous UnknownKeyPolicy = "anonymous"
+)
+
+// ParseUnknownKeyPolicy converte uma string de configuração em um
+// UnknownKeyPolicy. Uma string vazia resulta em UnknownKeyAllow.
+func ParseUnknownKeyPolicy(value string) (UnknownKeyPolicy, error) {
+	switch UnknownKeyPolicy(value) {
+	case "":
+		return UnknownKeyAllow, nil
+	case UnknownKeyAllow, UnknownKeyReject, UnknownKeyIP, UnknownKeyAnonymous:
+		return UnknownKeyPolicy(value), nil
+	default:
+		return "", fmt.Errorf("política de API key desconhecida inválida: %s", value)
+	}
+}
@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/JMKobayashi/Rate-Limiter-GO/internal/entity"
+	"github.com/JMKobayashi/Rate-Limiter-GO/internal/limiter/strategy"
+	"github.com/JMKobayashi/Rate-Limiter-GO/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// MockRecordingUseCase registra o identificador usado em cada requisição
+type MockRecordingUseCase struct {
+	identifier string
+	isToken    bool
+	keySuffix  string
+}
+
+func (m *MockRecordingUseCase) IsAllowed(ctx context.Context, identifier string, isToken bool) (bool, error) {
+	m.identifier = identifier
+	m.isToken = isToken
+	m.keySuffix = usecase.KeySuffix(ctx)
+	return true, nil
+}
+
+func TestRateLimiterMiddleware_UnknownKeyPolicy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	registry, err := strategy.NewMemoryTokenRegistry(entity.TokenPolicy{Token: "abc123", Limit: 100})
+	require.NoError(t, err)
+	validator := RegistryKeyValidator(registry)
+
+	tests := []struct {
+		name               string
+		policy             UnknownKeyPolicy
+		apiKey             string
+		expectedStatus     int
+		expectedIdentifier string
+		expectedIsToken    bool
+	}{
+		{"Known key", UnknownKeyReject, "abc123", http.StatusOK, "abc123", true},
+		{"Unknown key allowed", UnknownKeyAllow, "random", http.StatusOK, "random", true},
+		{"Unknown key rejected", UnknownKeyReject, "random", http.StatusUnauthorized, "", false},
+		{"Unknown key limited by IP", UnknownKeyIP, "random", http.StatusOK, "192.168.1.1", false},
+		{"Unknown key limited as anonymous", UnknownKeyAnonymous, "random", http.StatusOK, AnonymousToken, true},
+		{"No key", UnknownKeyReject, "", http.StatusOK, "192.168.1.1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCase := &MockRecordingUseCase{}
+			router := gin.New()
+			router.Use(RateLimiter(useCase, WithKeyValidator(validator, tt.policy)))
+			router.GET("/", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = "192.168.1.1:1234"
+			if tt.apiKey != "" {
+				req.Header.Set("API_KEY", tt.apiKey)
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Equal(t, tt.expectedIdentifier, useCase.identifier)
+			assert.Equal(t, tt.expectedIsToken, useCase.isToken)
+			if tt.expectedStatus == http.StatusUnauthorized {
+				assert.JSONEq(t, `{"error":"`+errInvalidAPIKey+`"}`, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestRateLimiterMiddleware_KeyValidatorError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	validator := KeyValidatorFunc(func(ctx context.Context, key string) (bool, error) {
+		return false, errors.New("redis down")
+	})
+
+	router := gin.New()
+	router.Use(RateLimiter(&MockUseCase{allowed: true}, WithKeyValidator(validator, UnknownKeyReject)))
+	router.GET("/", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("API_KEY", "abc123")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+}
+
+func TestParseUnknownKeyPolicy(t *testing.T) {
+	for value, expected := range map[string]UnknownKeyPolicy{
+		"":          UnknownKeyAllow,
+		"allow":     UnknownKeyAllow,
+		"reject":    UnknownKeyReject,
+		"ip":        UnknownKeyIP,
+		"anonymous": UnknownKeyAnonymous,
+	} {
+		policy, err := ParseUnknownKeyPolicy(value)
+		require.NoError(t, err)
+		assert.Equal(t, expected, policy)
+	}
+
+	_, err := ParseUnknownKeyPolicy("block")
+	assert.Error(t, err)
+}
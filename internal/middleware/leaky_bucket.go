@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrQueueFull é retornado quando a fila do leaky bucket não comporta mais requisições
+var ErrQueueFull = errors.New("fila do leaky bucket cheia")
+
+// sweepThreshold é o número de chaves a partir do qual as filas vazias são removidas
+const sweepThreshold = 10000
+
+// LeakyBucket segura as requisições acima da taxa em uma fila por chave e as
+// libera no ritmo de escoamento, em vez de rejeitá-las
+type LeakyBucket struct {
+	interval time.Duration
+	maxQueue int
+	maxWait  time.Duration
+	now      func() time.Time
+
+	mu   sync.Mutex
+	next map[string]time.Time
+}
+
+// NewLeakyBucket cria um leaky bucket que escoa rate requisições por segundo,
+// com no máximo maxQueue requisições aguardando por chave e espera máxima de
+// maxWait (zero desabilita o limite de espera)
+func NewLeakyBucket(rate float64, maxQueue int, maxWait time.Duration) *LeakyBucket {
+	return &LeakyBucket{
+		interval: time.Duration(float64(time.Second) / rate),
+		maxQueue: maxQueue,
+		maxWait:  maxWait,
+		now:      time.Now,
+		next:     make(map[string]time.Time),
+	}
+}
+
+// Wait reserva a vez da requisição na fila da chave e aguarda sua liberação.
+// Retorna ErrQueueFull se a fila estiver cheia ou a espera exceder o máximo,
+// ou o erro do contexto se a requisição for cancelada enquanto aguarda.
+func (b *LeakyBucket) Wait(ctx context.Context, key string) error {
+	b.mu.Lock()
+	now := b.now()
+
+	next, exists := b.next[key]
+	if !exists || next.Before(now) {
+		next = now
+	}
+
+	// Posição na fila: quantas requisições serão liberadas antes desta
+	delay := next.Sub(now)
+	position := int((delay + b.interval - 1) / b.interval)
+	if position > b.maxQueue || (b.maxWait > 0 && delay > b.maxWait) {
+		b.mu.Unlock()
+		return ErrQueueFull
+	}
+
+	reserved := next.Add(b.interval)
+	b.next[key] = reserved
+	b.sweep(now)
+	b.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		b.cancel(key, reserved)
+		return ctx.Err()
+	}
+}
+
+// cancel devolve a vez reservada quando ela ainda é a última da fila
+func (b *LeakyBucket) cancel(key string, reserved time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.next[key].Equal(reserved) {
+		b.next[key] = reserved.Add(-b.interval)
+	}
+}
+
+// sweep remove as chaves cujas filas já escoaram. Deve ser chamado com o lock adquirido.
+func (b *LeakyBucket) sweep(now time.Time) {
+	if len(b.next) < sweepThreshold {
+		return
+	}
+
+	for key, next := range b.next {
+		if next.Before(now) {
+			delete(b.next, key)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// reservations retorna quantas requisições estão reservadas na fila da chave
+func reservations(b *LeakyBucket, key string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delay := time.Until(b.next[key])
+	if delay <= 0 {
+		return 0
+	}
+	return int((delay + b.interval - 1) / b.interval)
+}
+
+func TestLeakyBucket_DrainRate(t *testing.T) {
+	bucket := NewLeakyBucket(20, 10, 0)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, bucket.Wait(context.Background(), "ip:192.168.1.1"))
+		}()
+	}
+	wg.Wait()
+
+	// 4 requisições a 20 req/s: a última sai após 3 intervalos de 50ms
+	assert.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond)
+}
+
+func TestLeakyBucket_IndependentKeys(t *testing.T) {
+	bucket := NewLeakyBucket(1, 0, 0)
+
+	require.NoError(t, bucket.Wait(context.Background(), "ip:192.168.1.1"))
+	require.NoError(t, bucket.Wait(context.Background(), "ip:192.168.1.2"))
+	assert.ErrorIs(t, bucket.Wait(context.Background(), "ip:192.168.1.1"), ErrQueueFull)
+}
+
+func TestLeakyBucket_QueueFull(t *testing.T) {
+	bucket := NewLeakyBucket(1, 2, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// A primeira requisição é liberada imediatamente
+	require.NoError(t, bucket.Wait(ctx, "token:abc123"))
+
+	// Duas requisições aguardam na fila
+	for i := 0; i < 2; i++ {
+		go func() {
+			_ = bucket.Wait(ctx, "token:abc123")
+		}()
+	}
+	require.Eventually(t, func() bool {
+		return reservations(bucket, "token:abc123") == 3
+	}, time.Second, time.Millisecond)
+
+	// A fila está cheia
+	assert.ErrorIs(t, bucket.Wait(ctx, "token:abc123"), ErrQueueFull)
+}
+
+func TestLeakyBucket_MaxWait(t *testing.T) {
+	bucket := NewLeakyBucket(1, 10, 1500*time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.NoError(t, bucket.Wait(ctx, "token:abc123"))
+	go func() {
+		_ = bucket.Wait(ctx, "token:abc123")
+	}()
+	require.Eventually(t, func() bool {
+		return reservations(bucket, "token:abc123") == 2
+	}, time.Second, time.Millisecond)
+
+	// A terceira requisição teria que esperar 2 segundos
+	assert.ErrorIs(t, bucket.Wait(ctx, "token:abc123"), ErrQueueFull)
+}
+
+func TestLeakyBucket_ContextCancelled(t *testing.T) {
+	bucket := NewLeakyBucket(1, 10, 0)
+
+	require.NoError(t, bucket.Wait(context.Background(), "ip:192.168.1.1"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := bucket.Wait(ctx, "ip:192.168.1.1")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// A vez cancelada é devolvida à fila
+	assert.Equal(t, 1, reservations(bucket, "ip:192.168.1.1"))
+}
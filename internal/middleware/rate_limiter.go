@@ -1,63 +1,413 @@
 package middleware
 
 import (
+	"errors"
+	"net"
 	"net/http"
+	"time"
 
+	"github.com/JMKobayashi/Rate-Limiter-GO/internal/entity"
 	"github.com/JMKobayashi/Rate-Limiter-GO/internal/usecase"
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	// errRateLimited é a mensagem retornada quando o limite é excedido
+	errRateLimited = "you have reached the maximum number of requests or actions allowed within a certain time frame"
+	// errRequestCancelled é a mensagem retornada quando a requisição é cancelada na fila
+	errRequestCancelled = "request cancelled while waiting in the rate limiter queue"
+	// errQuotaExceeded é a mensagem retornada quando uma cota de longo prazo é esgotada
+	errQuotaExceeded = "you have exhausted your request quota for the current period"
+	// errAccessDenied é a mensagem retornada quando o IP está na lista de bloqueio
+	errAccessDenied = "access denied"
+	// errInvalidClientIP é a mensagem retornada quando o IP do cliente não pode ser determinado
+	errInvalidClientIP = "invalid client IP"
+	// errInvalidCredentials é a mensagem retornada quando as credenciais, como um JWT, são inválidas
+	errInvalidCredentials = "invalid credentials"
+	// errInvalidAPIKey é a mensagem retornada quando a API key é desconhecida
+	errInvalidAPIKey = "invalid API key"
+	// errTooManyConcurrent é a mensagem retornada quando o limite de requisições simultâneas é atingido
+	errTooManyConcurrent = "you have reached the maximum number of concurrent requests allowed"
+	// errStorageUnavailable é a mensagem retornada quando o armazenamento do rate limiter falha
+	errStorageUnavailable = "rate limiter temporarily unavailable"
+)
+
 // RateLimiterMiddleware é um middleware para limitar requisições
 type RateLimiterMiddleware struct {
 	rateLimiterUseCase usecase.RateLimiterUseCaseInterface
+	leakyBucket        *LeakyBucket
+	concurrencyLimiter usecase.ConcurrencyLimiterUseCaseInterface
+	routeCosts         RouteCosts
+	routeRules         RouteRules
+	keyFunc            KeyFunc
+	headerStyle        HeaderStyle
+	identityExtractor  IdentityExtractor
+	credentialSources  []CredentialSource
+	keyValidator       KeyValidator
+	unknownKeyPolicy   UnknownKeyPolicy
+	clientIPResolver   *ClientIPResolver
+	ipAccessList       *IPAccessList
+	failurePolicy      FailurePolicy
+	failureRetryAfter  time.Duration
+	storageTimeout     time.Duration
+}
+
+// Option configura parâmetros opcionais do middleware
+type Option func(*RateLimiterMiddleware)
+
+// WithLeakyBucket habilita o modo leaky bucket: requisições acima da taxa do
+// balde aguardam em fila até serem liberadas, em vez de serem rejeitadas
+func WithLeakyBucket(bucket *LeakyBucket) Option {
+	return func(m *RateLimiterMiddleware) {
+		m.leakyBucket = bucket
+	}
+}
+
+// WithConcurrencyLimiter limita as requisições simultâneas por identificador:
+// uma vaga é ocupada antes de executar a requisição e liberada ao final
+func WithConcurrencyLimiter(limiter usecase.ConcurrencyLimiterUseCaseInterface) Option {
+	return func(m *RateLimiterMiddleware) {
+		m.concurrencyLimiter = limiter
+	}
+}
+
+// WithRouteCosts define quantas unidades da cota cada rota consome, para que
+// chamadas caras esgotem a cota mais rápido. Requer um caso de uso que
+// implemente usecase.WeightedRateLimiterUseCaseInterface.
+func WithRouteCosts(costs RouteCosts) Option {
+	return func(m *RateLimiterMiddleware) {
+		m.routeCosts = costs
+	}
+}
+
+// WithRouteRules define limites próprios para algumas rotas. Cada regra usa o
+// seu próprio caso de uso, com chaves separadas; as demais rotas usam o caso
+// de uso padrão do middleware.
+func WithRouteRules(rules RouteRules) Option {
+	return func(m *RateLimiterMiddleware) {
+		m.routeRules = rules
+	}
+}
+
+// WithKeyFunc define como o identificador limitado é composto, permitindo
+// combinar a API key ou o IP com outras dimensões da requisição. Regras por
+// rota com escopo ip continuam limitando apenas pelo IP.
+func WithKeyFunc(keyFunc KeyFunc) Option {
+	return func(m *RateLimiterMiddleware) {
+		m.keyFunc = keyFunc
+	}
+}
+
+// WithHeaderStyle define quais headers de rate limit são enviados: os
+// X-RateLimit-*, os RateLimit/RateLimit-Policy do draft IETF ou ambos
+func WithHeaderStyle(style HeaderStyle) Option {
+	return func(m *RateLimiterMiddleware) {
+		m.headerStyle = style
+	}
+}
+
+// WithIdentityExtractor obtém a identidade de token das credenciais da
+// requisição, como um JWT, antes de consultar o header API_KEY. Credenciais
+// inválidas são rejeitadas com 401; requisições sem credenciais seguem para
+// a API key ou o IP.
+func WithIdentityExtractor(extractor IdentityExtractor) Option {
+	return func(m *RateLimiterMiddleware) {
+		m.identityExtractor = extractor
+	}
+}
+
+// WithCredentialSources define onde a API key é procurada, em ordem de
+// prioridade: headers, Authorization: Bearer, query string ou cookies. Por
+// padrão apenas o header API_KEY é lido.
+func WithCredentialSources(sources ...CredentialSource) Option {
+	return func(m *RateLimiterMiddleware) {
+		if len(sources) > 0 {
+			m.credentialSources = sources
+		}
+	}
+}
+
+// WithKeyValidator valida as API keys recebidas e define como tratar as
+// desconhecidas: rejeitar com 401, limitar pelo IP ou limitar em um token
+// anônimo compartilhado
+func WithKeyValidator(validator KeyValidator, policy UnknownKeyPolicy) Option {
+	return func(m *RateLimiterMiddleware) {
+		m.keyValidator = validator
+		m.unknownKeyPolicy = policy
+	}
+}
+
+// WithClientIPResolver define como o IP do cliente é obtido, consultando os
+// headers de proxy apenas para conexões vindas de proxies confiáveis. Sem
+// ele, o middleware usa c.ClientIP() com a configuração de proxies do gin.
+func WithClientIPResolver(resolver *ClientIPResolver) Option {
+	return func(m *RateLimiterMiddleware) {
+		m.clientIPResolver = resolver
+	}
+}
+
+// WithIPAccessList consulta o IP do cliente nas listas de acesso antes do
+// rate limiter: IPs permitidos não são limitados e IPs bloqueados recebem 403
+func WithIPAccessList(list *IPAccessList) Option {
+	return func(m *RateLimiterMiddleware) {
+		m.ipAccessList = list
+	}
+}
+
+// WithFailurePolicy define o tratamento das requisições quando o
+// armazenamento falha: rejeitar com 503 e o Retry-After informado ou liberar
+// sem limitação. Por padrão as requisições são rejeitadas.
+func WithFailurePolicy(policy FailurePolicy, retryAfter time.Duration) Option {
+	return func(m *RateLimiterMiddleware) {
+		m.failurePolicy = policy
+		if retryAfter > 0 {
+			m.failureRetryAfter = retryAfter
+		}
+	}
+}
+
+// WithStorageTimeout limita o tempo de cada operação de armazenamento da
+// requisição; uma operação que excede o limite é tratada como uma falha
+func WithStorageTimeout(timeout time.Duration) Option {
+	return func(m *RateLimiterMiddleware) {
+		m.storageTimeout = timeout
+	}
 }
 
 // NewRateLimiterMiddleware cria um novo middleware de rate limiter
-func NewRateLimiterMiddleware(rateLimiterUseCase usecase.RateLimiterUseCaseInterface) *RateLimiterMiddleware {
-	return &RateLimiterMiddleware{
+func NewRateLimiterMiddleware(rateLimiterUseCase usecase.RateLimiterUseCaseInterface, opts ...Option) *RateLimiterMiddleware {
+	m := &RateLimiterMiddleware{
 		rateLimiterUseCase: rateLimiterUseCase,
+		headerStyle:        LegacyHeaders,
+		unknownKeyPolicy:   UnknownKeyAllow,
+		credentialSources:  []CredentialSource{HeaderCredential(DefaultTokenHeader)},
+		failurePolicy:      FailClosed,
+		failureRetryAfter:  DefaultFailureRetryAfter,
 	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
 }
 
-func RateLimiter(useCase usecase.RateLimiterUseCaseInterface) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// Verifica o token primeiro
-		token := c.GetHeader("API_KEY")
-		if token != "" {
-			allowed, err := useCase.IsAllowed(c.Request.Context(), token, true)
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
-				c.Abort()
+func RateLimiter(useCase usecase.RateLimiterUseCaseInterface, opts ...Option) gin.HandlerFunc {
+	return NewRateLimiterMiddleware(useCase, opts...).Handle
+}
+
+// Handle aplica o rate limiter à requisição
+func (m *RateLimiterMiddleware) Handle(c *gin.Context) {
+	// Redes permitidas e bloqueadas não passam pelo rate limiter
+	if m.ipAccessList != nil {
+		switch m.ipAccessList.Lookup(net.ParseIP(m.resolveClientIP(c))) {
+		case IPAccessDenied:
+			c.JSON(http.StatusForbidden, gin.H{"error": errAccessDenied})
+			c.Abort()
+			return
+		case IPAccessAllowed:
+			c.Next()
+			return
+		}
+	}
+
+	identifier, isToken, ok := m.identify(c)
+	if !ok {
+		return
+	}
+
+	// Rotas com regra própria usam o caso de uso da regra
+	useCase := m.rateLimiterUseCase
+	rule, hasRule := m.routeRules.match(c)
+	if hasRule {
+		useCase = rule.UseCase
+	}
+
+	switch {
+	case hasRule && rule.Scope == ScopeIP:
+		if isToken {
+			identifier, isToken, ok = m.clientIP(c)
+			if !ok {
 				return
 			}
-			if !allowed {
-				c.JSON(http.StatusTooManyRequests, gin.H{
-					"error": "you have reached the maximum number of requests or actions allowed within a certain time frame",
-				})
-				c.Abort()
-				return
+		}
+	case m.keyFunc != nil:
+		// O identificador base segue para o registro de tokens e a agregação
+		// por rede; as demais dimensões só compõem a chave de armazenamento
+		suffix := m.keyFunc.Key(c, Identity{
+			Identifier: identifier,
+			IsToken:    isToken,
+			ClientIP:   m.resolveClientIP(c),
+		})
+		if suffix != identifier {
+			c.Request = c.Request.WithContext(usecase.ContextWithKeySuffix(c.Request.Context(), suffix))
+		}
+	}
+
+	// No modo leaky bucket, aguarda a vez da requisição na fila
+	if m.leakyBucket != nil {
+		key := map[bool]string{true: "token", false: "ip"}[isToken] + ":" + identifier
+		if suffix := usecase.KeySuffix(c.Request.Context()); suffix != "" {
+			key += keySeparator + suffix
+		}
+		if err := m.leakyBucket.Wait(c.Request.Context(), key); err != nil {
+			if errors.Is(err, ErrQueueFull) {
+				c.JSON(http.StatusTooManyRequests, gin.H{"error": errRateLimited})
+			} else {
+				c.JSON(http.StatusServiceUnavailable, gin.H{"error": errRequestCancelled})
 			}
-			c.Next()
+			c.Abort()
 			return
 		}
+	}
 
-		// Se não tem token, verifica o IP
-		ip := c.ClientIP()
-		allowed, err := useCase.IsAllowed(c.Request.Context(), ip, false)
+	decision, err := m.decide(c, useCase, identifier, isToken)
+	if err != nil {
+		if m.storageFailure(c, err) {
+			c.Next()
+		}
+		return
+	}
+
+	setRateLimitHeaders(c, decision, m.headerStyle)
+	if !decision.Allowed {
+		if decision.Reason == usecase.ReasonQuotaExceeded {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": errQuotaExceeded, "quota": decision.Policy})
+		} else {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": errRateLimited})
+		}
+		c.Abort()
+		return
+	}
+
+	// Ocupa uma vaga de requisição em andamento até o fim da requisição
+	if m.concurrencyLimiter != nil {
+		ctx, cancel := m.storageContext(c)
+		release, acquired, err := m.concurrencyLimiter.Acquire(ctx, identifier, isToken)
+		cancel()
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			if m.storageFailure(c, err) {
+				c.Next()
+			}
+			return
+		}
+		if !acquired {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": errTooManyConcurrent})
 			c.Abort()
 			return
 		}
+		defer release()
+	}
+
+	c.Next()
+}
+
+// decide consome a cota do identificador de acordo com o custo da rota
+func (m *RateLimiterMiddleware) decide(c *gin.Context, useCase usecase.RateLimiterUseCaseInterface, identifier string, isToken bool) (*usecase.Decision, error) {
+	ctx, cancel := m.storageContext(c)
+	defer cancel()
 
-		if !allowed {
-			c.JSON(http.StatusTooManyRequests, gin.H{
-				"error": "you have reached the maximum number of requests or actions allowed within a certain time frame",
-			})
+	return usecase.Decide(ctx, useCase, identifier, isToken, m.routeCosts.costOf(c))
+}
+
+// identify define o identificador da requisição: a API key, quando presente
+// e aceita pela política de API keys desconhecidas, ou o IP do cliente.
+// Retorna ok falso quando a requisição já foi respondida.
+func (m *RateLimiterMiddleware) identify(c *gin.Context) (identifier string, isToken bool, ok bool) {
+	// Credenciais verificadas, como um JWT, têm precedência sobre a API key
+	if m.identityExtractor != nil {
+		identity, err := m.identityExtractor.Extract(c)
+		if err != nil {
+			if errors.Is(err, ErrInvalidCredentials) {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": errInvalidCredentials})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			}
 			c.Abort()
-			return
+			return "", false, false
+		}
+		if identity != nil {
+			// O limite próprio da identidade chega ao caso de uso pelo contexto
+			if identity.Limit > 0 {
+				c.Request = c.Request.WithContext(usecase.ContextWithTokenPolicy(c.Request.Context(),
+					&entity.TokenPolicy{Token: identity.Token, Limit: identity.Limit}))
+			}
+			return identity.Token, true, true
+		}
+	}
+
+	// Verifica o token primeiro; se não tem token, verifica o IP
+	key := m.apiKey(c)
+	if key == "" {
+		return m.clientIP(c)
+	}
+
+	if m.keyValidator == nil || m.unknownKeyPolicy == UnknownKeyAllow {
+		return key, true, true
+	}
+
+	ctx, cancel := m.storageContext(c)
+	valid, err := m.keyValidator.ValidateKey(ctx, key)
+	cancel()
+	if err != nil {
+		// Com a política FailOpen, a API key é aceita sem validação
+		if m.storageFailure(c, err) {
+			return key, true, true
+		}
+		return "", false, false
+	}
+	if valid {
+		return key, true, true
+	}
+
+	switch m.unknownKeyPolicy {
+	case UnknownKeyIP:
+		return m.clientIP(c)
+	case UnknownKeyAnonymous:
+		return AnonymousToken, true, true
+	default:
+		c.JSON(http.StatusUnauthorized, gin.H{"error": errInvalidAPIKey})
+		c.Abort()
+		return "", false, false
+	}
+}
+
+// apiKey retorna a API key da primeira fonte que a contém
+func (m *RateLimiterMiddleware) apiKey(c *gin.Context) string {
+	for _, source := range m.credentialSources {
+		if key := source.Credential(c); key != "" {
+			return key
 		}
+	}
+	return ""
+}
 
-		c.Next()
+// clientIP retorna o IP do cliente como identificador. Requisições cujo IP
+// não pode ser determinado são rejeitadas com 400.
+func (m *RateLimiterMiddleware) clientIP(c *gin.Context) (string, bool, bool) {
+	ip := m.resolveClientIP(c)
+	if net.ParseIP(ip) == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errInvalidClientIP})
+		c.Abort()
+		return "", false, false
+	}
+
+	return ip, false, true
+}
+
+// resolveClientIP retorna o IP do cliente, ou uma string vazia se ele não
+// puder ser determinado
+func (m *RateLimiterMiddleware) resolveClientIP(c *gin.Context) string {
+	if m.clientIPResolver != nil {
+		return m.clientIPResolver.ClientIP(c.Request)
+	}
+
+	ip := c.ClientIP()
+	if ip == "" {
+		// O gin exige host:porta; aceita também um IP sem porta
+		if remote := parseIP(c.Request.RemoteAddr); remote != nil {
+			ip = remote.String()
+		}
 	}
+	return ip
 }
@@ -5,12 +5,16 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/JMKobayashi/Rate-Limiter-GO/internal/limiter/strategy"
 	"github.com/JMKobayashi/Rate-Limiter-GO/internal/usecase"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type MockUseCase struct {
@@ -78,7 +82,7 @@ func TestRateLimiter(t *testing.T) {
 				err:     fmt.Errorf("test error"),
 			},
 			headers:        map[string]string{},
-			expectedStatus: http.StatusInternalServerError,
+			expectedStatus: http.StatusServiceUnavailable,
 		},
 	}
 
@@ -160,7 +164,7 @@ func TestRateLimiterMiddleware(t *testing.T) {
 			token:          "",
 			mockAllowed:    false,
 			mockError:      assert.AnError,
-			expectedStatus: http.StatusInternalServerError,
+			expectedStatus: http.StatusServiceUnavailable,
 		},
 	}
 
@@ -325,3 +329,340 @@ func TestRateLimiterMiddleware_NoIP(t *testing.T) {
 	// Verificar status code
 	assert.Equal(t, http.StatusBadRequest, rr.Code)
 }
+
+func TestRateLimiterMiddleware_LeakyBucket(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRouter := func(bucket *LeakyBucket) *gin.Engine {
+		router := gin.New()
+		router.Use(RateLimiter(&MockUseCase{allowed: true}, WithLeakyBucket(bucket)))
+		router.GET("/", func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+		return router
+	}
+
+	t.Run("Requests over the rate are delayed", func(t *testing.T) {
+		router := newRouter(NewLeakyBucket(20, 5, time.Second))
+
+		start := time.Now()
+		var wg sync.WaitGroup
+		codes := make(chan int, 3)
+		for i := 0; i < 3; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				req := httptest.NewRequest("GET", "/", nil)
+				req.Header.Set("API_KEY", "batch-client")
+				rr := httptest.NewRecorder()
+				router.ServeHTTP(rr, req)
+				codes <- rr.Code
+			}()
+		}
+		wg.Wait()
+		close(codes)
+
+		for code := range codes {
+			assert.Equal(t, http.StatusOK, code)
+		}
+		assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+	})
+
+	t.Run("Queue full", func(t *testing.T) {
+		router := newRouter(NewLeakyBucket(1, 0, 0))
+
+		for _, expected := range []int{http.StatusOK, http.StatusTooManyRequests} {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("API_KEY", "batch-client")
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+			assert.Equal(t, expected, rr.Code)
+		}
+	})
+
+	t.Run("Request cancelled while waiting", func(t *testing.T) {
+		router := newRouter(NewLeakyBucket(1, 5, 0))
+
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("API_KEY", "batch-client")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		req = httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+		req.Header.Set("API_KEY", "batch-client")
+		rr = httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	})
+}
+
+func TestRateLimiterMiddleware_ConcurrencyLimiter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo, err := strategy.NewRepository(strategy.MemoryRepository, nil)
+	require.NoError(t, err)
+	limiter := usecase.NewConcurrencyLimiterUseCase(repo.(strategy.ConcurrencyRepository), 0, 1, time.Minute)
+
+	entered := make(chan struct{})
+	unblock := make(chan struct{})
+
+	router := gin.New()
+	router.Use(RateLimiter(&MockUseCase{allowed: true}, WithConcurrencyLimiter(limiter)))
+	router.GET("/slow", func(c *gin.Context) {
+		entered <- struct{}{}
+		<-unblock
+		c.Status(http.StatusOK)
+	})
+	router.GET("/", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	request := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("API_KEY", "test-token")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Uma requisição lenta ocupa a única vaga do token
+	done := make(chan int)
+	go func() {
+		done <- request("/slow").Code
+	}()
+	<-entered
+
+	rr := request("/")
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Contains(t, rr.Body.String(), errTooManyConcurrent)
+
+	// Ao terminar, a vaga é liberada
+	close(unblock)
+	assert.Equal(t, http.StatusOK, <-done)
+	assert.Equal(t, http.StatusOK, request("/").Code)
+}
+
+// MockDecisionUseCase retorna uma decisão fixa com o motivo da rejeição
+type MockDecisionUseCase struct {
+	MockUseCase
+	decision *usecase.Decision
+}
+
+func (m *MockDecisionUseCase) Decide(ctx context.Context, identifier string, isToken bool, n int) (*usecase.Decision, error) {
+	return m.decision, m.err
+}
+
+func TestRateLimiterMiddleware_QuotaExceeded(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		decision *usecase.Decision
+		body     string
+	}{
+		{
+			name:     "Rate limited",
+			decision: &usecase.Decision{Allowed: false, Reason: usecase.ReasonRateLimited},
+			body:     `{"error":"` + errRateLimited + `"}`,
+		},
+		{
+			name:     "Quota exceeded",
+			decision: &usecase.Decision{Allowed: false, Reason: usecase.ReasonQuotaExceeded, Policy: "monthly"},
+			body:     `{"error":"` + errQuotaExceeded + `","quota":"monthly"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(RateLimiter(&MockDecisionUseCase{decision: tt.decision}))
+			router.GET("/", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("API_KEY", "test-token")
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+			assert.JSONEq(t, tt.body, rr.Body.String())
+		})
+	}
+}
+
+func TestRateLimiterMiddleware_Headers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	resetAt := time.Unix(1700000000, 500*int64(time.Millisecond))
+	tests := []struct {
+		name           string
+		decision       *usecase.Decision
+		expectedStatus int
+		expected       map[string]string
+	}{
+		{
+			name:           "Allowed request",
+			decision:       &usecase.Decision{Allowed: true, Limit: 10, Remaining: 7, ResetAt: resetAt},
+			expectedStatus: http.StatusOK,
+			expected: map[string]string{
+				"X-RateLimit-Limit":     "10",
+				"X-RateLimit-Remaining": "7",
+				"X-RateLimit-Reset":     "1700000001",
+				"Retry-After":           "",
+			},
+		},
+		{
+			name: "Blocked request",
+			decision: &usecase.Decision{
+				Allowed:      false,
+				Reason:       usecase.ReasonRateLimited,
+				Limit:        10,
+				ResetAt:      resetAt,
+				RetryAfter:   299500 * time.Millisecond,
+				BlockedUntil: resetAt.Add(299 * time.Second),
+			},
+			expectedStatus: http.StatusTooManyRequests,
+			expected: map[string]string{
+				"X-RateLimit-Limit":     "10",
+				"X-RateLimit-Remaining": "0",
+				"X-RateLimit-Reset":     "1700000001",
+				"Retry-After":           "300",
+			},
+		},
+		{
+			name:           "Limiter disabled",
+			decision:       &usecase.Decision{Allowed: true},
+			expectedStatus: http.StatusOK,
+			expected: map[string]string{
+				"X-RateLimit-Limit":     "",
+				"X-RateLimit-Remaining": "",
+				"X-RateLimit-Reset":     "",
+				"Retry-After":           "",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(RateLimiter(&MockDecisionUseCase{decision: tt.decision}))
+			router.GET("/", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("API_KEY", "test-token")
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			for header, value := range tt.expected {
+				assert.Equal(t, value, rr.Header().Get(header), header)
+			}
+		})
+	}
+}
+
+func TestRateLimiterMiddleware_IETFHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	resetAt := time.Now().Add(30 * time.Second)
+	decision := &usecase.Decision{
+		Allowed:   true,
+		Limit:     100,
+		Remaining: 99,
+		ResetAt:   resetAt,
+		Policies: []usecase.PolicyStatus{
+			{Name: "token", Limit: 100, Window: time.Second, Remaining: 99, ResetAt: resetAt},
+			{Name: "monthly", Limit: 1000000, Window: 31 * 24 * time.Hour, Remaining: 999999, ResetAt: resetAt},
+		},
+	}
+
+	tests := []struct {
+		style  HeaderStyle
+		legacy bool
+		ietf   bool
+	}{
+		{style: LegacyHeaders, legacy: true},
+		{style: IETFHeaders, ietf: true},
+		{style: BothHeaders, legacy: true, ietf: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.style), func(t *testing.T) {
+			router := gin.New()
+			router.Use(RateLimiter(&MockDecisionUseCase{decision: decision}, WithHeaderStyle(tt.style)))
+			router.GET("/", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("API_KEY", "test-token")
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, tt.legacy, rr.Header().Get("X-RateLimit-Limit") != "")
+			if tt.ietf {
+				assert.Equal(t, `"token";q=100;w=1, "monthly";q=1000000;w=2678400`, rr.Header().Get("RateLimit-Policy"))
+				assert.Equal(t, `"token";r=99;t=30, "monthly";r=999999;t=30`, rr.Header().Get("RateLimit"))
+			} else {
+				assert.Empty(t, rr.Header().Get("RateLimit-Policy"))
+				assert.Empty(t, rr.Header().Get("RateLimit"))
+			}
+		})
+	}
+}
+
+func TestRateLimiterMiddleware_BurstHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for _, algorithm := range []strategy.Algorithm{strategy.TokenBucket, strategy.GCRA} {
+		t.Run(string(algorithm), func(t *testing.T) {
+			repo, err := strategy.NewRepository(strategy.MemoryRepository, nil)
+			require.NoError(t, err)
+			useCase := usecase.NewRateLimiterUseCase(repo, 100, 100, 0, 0, true, true,
+				usecase.WithAlgorithms(algorithm, algorithm),
+				usecase.WithBurst(300, 300),
+			)
+
+			router := gin.New()
+			router.Use(RateLimiter(useCase, WithHeaderStyle(BothHeaders)))
+			router.GET("/", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("API_KEY", "abc123")
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			// O limite informado é a capacidade do balde, coerente com as restantes
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, "300", rr.Header().Get("X-RateLimit-Limit"))
+			assert.Equal(t, "299", rr.Header().Get("X-RateLimit-Remaining"))
+			assert.True(t, strings.HasPrefix(rr.Header().Get("RateLimit-Policy"), `"token";q=300;`), rr.Header().Get("RateLimit-Policy"))
+			assert.True(t, strings.HasPrefix(rr.Header().Get("RateLimit"), `"token";r=299;`), rr.Header().Get("RateLimit"))
+		})
+	}
+}
+
+func TestParseHeaderStyle(t *testing.T) {
+	for value, expected := range map[string]HeaderStyle{
+		"":       LegacyHeaders,
+		"legacy": LegacyHeaders,
+		"ietf":   IETFHeaders,
+		"both":   BothHeaders,
+	} {
+		style, err := ParseHeaderStyle(value)
+		require.NoError(t, err)
+		assert.Equal(t, expected, style)
+	}
+
+	_, err := ParseHeaderStyle("draft")
+	assert.Error(t, err)
+}
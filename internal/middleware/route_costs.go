@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RouteCosts associa rotas ao número de unidades da cota consumidas por
+// requisição. As chaves são "MÉTODO /rota" ou apenas "/rota" para qualquer
+// método, usando o padrão da rota registrado no gin (ex: "/users/:id").
+type RouteCosts map[string]int
+
+// ParseRouteCosts converte uma lista no formato "POST /export=50,/search=5"
+// em RouteCosts
+func ParseRouteCosts(spec string) (RouteCosts, error) {
+	costs := make(RouteCosts)
+
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		route, value, found := strings.Cut(entry, "=")
+		if !found {
+			return nil, fmt.Errorf("custo de rota inválido: %q", entry)
+		}
+
+		cost, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || cost < 1 {
+			return nil, fmt.Errorf("custo de rota inválido: %q", entry)
+		}
+
+		key, ok := parseRouteKey(route)
+		if !ok {
+			return nil, fmt.Errorf("custo de rota inválido: %q", entry)
+		}
+		costs[key] = cost
+	}
+
+	return costs, nil
+}
+
+// parseRouteKey normaliza uma rota no formato "MÉTODO /rota" ou "/rota"
+func parseRouteKey(route string) (string, bool) {
+	fields := strings.Fields(route)
+	switch len(fields) {
+	case 1:
+		return fields[0], true
+	case 2:
+		return strings.ToUpper(fields[0]) + " " + fields[1], true
+	default:
+		return "", false
+	}
+}
+
+// routeKeys retorna as chaves da requisição, da mais específica para a menos
+// específica: "MÉTODO /rota" e "/rota"
+func routeKeys(c *gin.Context) [2]string {
+	route := c.FullPath()
+	if route == "" {
+		route = c.Request.URL.Path
+	}
+	return [2]string{c.Request.Method + " " + route, route}
+}
+
+// costOf retorna o custo da requisição: primeiro pelo método e rota, depois
+// apenas pela rota. Requisições sem custo configurado custam 1.
+func (costs RouteCosts) costOf(c *gin.Context) int {
+	for _, key := range routeKeys(c) {
+		if cost, exists := costs[key]; exists {
+			return cost
+		}
+	}
+	return 1
+}
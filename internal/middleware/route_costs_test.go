@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// MockWeightedUseCase registra o custo de cada requisição
+type MockWeightedUseCase struct {
+	MockUseCase
+	costs []int
+}
+
+func (m *MockWeightedUseCase) AllowN(ctx context.Context, identifier string, isToken bool, n int) (bool, error) {
+	m.costs = append(m.costs, n)
+	return m.allowed, m.err
+}
+
+func (m *MockWeightedUseCase) IsAllowed(ctx context.Context, identifier string, isToken bool) (bool, error) {
+	return m.AllowN(ctx, identifier, isToken, 1)
+}
+
+func TestParseRouteCosts(t *testing.T) {
+	costs, err := ParseRouteCosts("POST /export=50, /search = 5,get /users/:id=2")
+	require.NoError(t, err)
+	assert.Equal(t, RouteCosts{
+		"POST /export":   50,
+		"/search":        5,
+		"GET /users/:id": 2,
+	}, costs)
+
+	costs, err = ParseRouteCosts("")
+	require.NoError(t, err)
+	assert.Empty(t, costs)
+
+	for _, spec := range []string{"POST /export", "/export=0", "/export=abc", "POST /export extra=5"} {
+		_, err := ParseRouteCosts(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestRateLimiterMiddleware_RouteCosts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	useCase := &MockWeightedUseCase{MockUseCase: MockUseCase{allowed: true}}
+	router := gin.New()
+	router.Use(RateLimiter(useCase, WithRouteCosts(RouteCosts{
+		"POST /export":   50,
+		"/users/:id":     2,
+		"GET /users/:id": 3,
+	})))
+	handler := func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	}
+	router.GET("/", handler)
+	router.POST("/export", handler)
+	router.GET("/export", handler)
+	router.GET("/users/:id", handler)
+	router.DELETE("/users/:id", handler)
+
+	requests := []struct {
+		method string
+		path   string
+	}{
+		{"GET", "/"},
+		{"POST", "/export"},
+		{"GET", "/export"},
+		{"GET", "/users/42"},
+		{"DELETE", "/users/42"},
+	}
+	for _, r := range requests {
+		req := httptest.NewRequest(r.method, r.path, nil)
+		req.Header.Set("API_KEY", "test-token")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusOK, rr.Code)
+	}
+
+	assert.Equal(t, []int{1, 50, 1, 3, 2}, useCase.costs)
+}
@@ -0,0 +1,134 @@
+package middleware
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JMKobayashi/Rate-Limiter-GO/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// RuleScope define o identificador limitado por uma regra
+type RuleScope string
+
+const (
+	// ScopeToken limita pela API key quando presente e, sem ela, pelo IP
+	ScopeToken RuleScope = "token"
+	// ScopeIP limita sempre pelo IP do cliente, mesmo com API key
+	ScopeIP RuleScope = "ip"
+)
+
+// RouteRule é uma regra de limitação própria de uma rota. A rota é
+// "MÉTODO /rota" ou apenas "/rota" para qualquer método, usando o padrão da
+// rota registrado no gin (ex: "/users/:id").
+type RouteRule struct {
+	Route   string
+	Scope   RuleScope
+	UseCase usecase.RateLimiterUseCaseInterface
+}
+
+// RouteRules associa rotas às suas regras. Requisições sem regra usam o caso
+// de uso padrão do middleware.
+type RouteRules map[string]RouteRule
+
+// RuleSpec descreve uma regra lida da configuração, antes da criação do seu caso de uso
+type RuleSpec struct {
+	Route  string
+	Limit  int
+	Window time.Duration
+	Scope  RuleScope
+}
+
+// Namespace retorna o namespace das chaves da regra, ex: "rule:POST:/login"
+func (s RuleSpec) Namespace() string {
+	return "rule:" + strings.ReplaceAll(s.Route, " ", ":")
+}
+
+// NewRouteRules cria as regras por rota, rejeitando rotas inválidas ou repetidas
+func NewRouteRules(rules ...RouteRule) (RouteRules, error) {
+	routeRules := make(RouteRules, len(rules))
+	for _, rule := range rules {
+		key, ok := parseRouteKey(rule.Route)
+		if !ok {
+			return nil, fmt.Errorf("rota inválida: %q", rule.Route)
+		}
+		if _, exists := routeRules[key]; exists {
+			return nil, fmt.Errorf("regra repetida para a rota %q", key)
+		}
+		if rule.UseCase == nil {
+			return nil, fmt.Errorf("regra sem caso de uso para a rota %q", key)
+		}
+		if rule.Scope == "" {
+			rule.Scope = ScopeToken
+		}
+
+		rule.Route = key
+		routeRules[key] = rule
+	}
+	return routeRules, nil
+}
+
+// ParseRuleSpecs converte uma lista no formato
+// "POST /login=5/1m:ip,GET /search=50/1s:token" em regras. O escopo é
+// opcional e por padrão é token.
+func ParseRuleSpecs(spec string) ([]RuleSpec, error) {
+	var specs []RuleSpec
+
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		route, value, found := strings.Cut(entry, "=")
+		if !found {
+			return nil, fmt.Errorf("regra de rota inválida: %q", entry)
+		}
+		key, ok := parseRouteKey(route)
+		if !ok {
+			return nil, fmt.Errorf("regra de rota inválida: %q", entry)
+		}
+
+		rate, scope, _ := strings.Cut(strings.TrimSpace(value), ":")
+		limit, window, found := strings.Cut(rate, "/")
+		if !found {
+			return nil, fmt.Errorf("regra de rota inválida: %q", entry)
+		}
+
+		var err error
+		ruleSpec := RuleSpec{Route: key, Scope: RuleScope(strings.ToLower(scope))}
+		ruleSpec.Limit, err = strconv.Atoi(limit)
+		if err != nil || ruleSpec.Limit < 1 {
+			return nil, fmt.Errorf("limite inválido na regra de rota: %q", entry)
+		}
+		ruleSpec.Window, err = time.ParseDuration(window)
+		if err != nil || ruleSpec.Window <= 0 {
+			return nil, fmt.Errorf("janela inválida na regra de rota: %q", entry)
+		}
+
+		switch ruleSpec.Scope {
+		case "":
+			ruleSpec.Scope = ScopeToken
+		case ScopeToken, ScopeIP:
+		default:
+			return nil, fmt.Errorf("escopo inválido na regra de rota: %q", entry)
+		}
+
+		specs = append(specs, ruleSpec)
+	}
+
+	return specs, nil
+}
+
+// match retorna a regra da requisição: primeiro pelo método e rota, depois
+// apenas pela rota
+func (rules RouteRules) match(c *gin.Context) (RouteRule, bool) {
+	for _, key := range routeKeys(c) {
+		if rule, exists := rules[key]; exists {
+			return rule, true
+		}
+	}
+	return RouteRule{}, false
+}
@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/JMKobayashi/Rate-Limiter-GO/internal/limiter/strategy"
+	"github.com/JMKobayashi/Rate-Limiter-GO/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRuleSpecs(t *testing.T) {
+	specs, err := ParseRuleSpecs("post /login=5/1m:ip, GET /search=50/1s:token, /export=2/1h")
+	require.NoError(t, err)
+	assert.Equal(t, []RuleSpec{
+		{Route: "POST /login", Limit: 5, Window: time.Minute, Scope: ScopeIP},
+		{Route: "GET /search", Limit: 50, Window: time.Second, Scope: ScopeToken},
+		{Route: "/export", Limit: 2, Window: time.Hour, Scope: ScopeToken},
+	}, specs)
+	assert.Equal(t, "rule:POST:/login", specs[0].Namespace())
+
+	specs, err = ParseRuleSpecs("")
+	require.NoError(t, err)
+	assert.Empty(t, specs)
+
+	for _, spec := range []string{"POST /login", "/login=5", "/login=0/1m", "/login=5/abc", "/login=5/1m:user", "POST /login extra=5/1m"} {
+		_, err := ParseRuleSpecs(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestNewRouteRules(t *testing.T) {
+	useCase := &MockUseCase{allowed: true}
+
+	rules, err := NewRouteRules(RouteRule{Route: "post /login", UseCase: useCase})
+	require.NoError(t, err)
+	assert.Equal(t, ScopeToken, rules["POST /login"].Scope)
+
+	_, err = NewRouteRules(
+		RouteRule{Route: "POST /login", UseCase: useCase},
+		RouteRule{Route: "POST  /login", UseCase: useCase},
+	)
+	assert.Error(t, err)
+
+	_, err = NewRouteRules(RouteRule{Route: "POST /login"})
+	assert.Error(t, err)
+}
+
+func TestRateLimiterMiddleware_RouteRules(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo, err := strategy.NewRepository(strategy.MemoryRepository, nil)
+	require.NoError(t, err)
+	newUseCase := func(limit int, namespace string) usecase.RateLimiterUseCaseInterface {
+		return usecase.NewRateLimiterUseCase(repo, limit, limit, 0, 0, true, true,
+			usecase.WithWindows(time.Minute, time.Minute),
+			usecase.WithAlgorithms(strategy.SlidingWindow, strategy.SlidingWindow),
+			usecase.WithKeyNamespace(namespace),
+		)
+	}
+
+	rules, err := NewRouteRules(
+		RouteRule{Route: "POST /login", Scope: ScopeIP, UseCase: newUseCase(2, "rule:POST:/login")},
+		RouteRule{Route: "GET /search", Scope: ScopeToken, UseCase: newUseCase(3, "rule:GET:/search")},
+	)
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(RateLimiter(newUseCase(5, ""), WithRouteRules(rules)))
+	handler := func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	}
+	router.POST("/login", handler)
+	router.GET("/search", handler)
+	router.GET("/", handler)
+
+	send := func(method, path, ip, apiKey string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.RemoteAddr = ip + ":1234"
+		if apiKey != "" {
+			req.Header.Set("API_KEY", apiKey)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	// A regra por IP ignora a API key: trocar de key não escapa do limite
+	assert.Equal(t, http.StatusOK, send("POST", "/login", "192.168.1.1", "key-1"))
+	assert.Equal(t, http.StatusOK, send("POST", "/login", "192.168.1.1", "key-2"))
+	assert.Equal(t, http.StatusTooManyRequests, send("POST", "/login", "192.168.1.1", "key-3"))
+	assert.Equal(t, http.StatusOK, send("POST", "/login", "192.168.1.2", ""))
+
+	// A regra por token tem contadores próprios para cada token
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusOK, send("GET", "/search", "192.168.1.1", "key-1"))
+	}
+	assert.Equal(t, http.StatusTooManyRequests, send("GET", "/search", "192.168.1.1", "key-1"))
+	assert.Equal(t, http.StatusOK, send("GET", "/search", "192.168.1.1", "key-2"))
+
+	// A regra padrão não compartilha contadores com as regras por rota
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, http.StatusOK, send("GET", "/", "192.168.1.1", "key-1"))
+	}
+	assert.Equal(t, http.StatusTooManyRequests, send("GET", "/", "192.168.1.1", "key-1"))
+}
+
+func TestRateLimiterMiddleware_RouteRulesQuota(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo, err := strategy.NewRepository(strategy.MemoryRepository, nil)
+	require.NoError(t, err)
+	newUseCase := func(limit int, namespace string) usecase.RateLimiterUseCaseInterface {
+		return usecase.NewRateLimiterUseCase(repo, limit, limit, 0, 0, true, true,
+			usecase.WithKeyNamespace(namespace),
+			usecase.WithQuota(3, usecase.DailyQuota),
+		)
+	}
+
+	rules, err := NewRouteRules(
+		RouteRule{Route: "GET /search", Scope: ScopeToken, UseCase: newUseCase(50, "rule:GET:/search")},
+	)
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(RateLimiter(newUseCase(50, ""), WithRouteRules(rules)))
+	handler := func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	}
+	router.GET("/search", handler)
+	router.GET("/", handler)
+
+	send := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.RemoteAddr = "192.168.1.1:1234"
+		req.Header.Set("API_KEY", "abc123")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// A rota com regra própria consome a mesma cota diária do token
+	assert.Equal(t, http.StatusOK, send("/").Code)
+	assert.Equal(t, http.StatusOK, send("/search").Code)
+	assert.Equal(t, http.StatusOK, send("/search").Code)
+
+	rr := send("/search")
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Contains(t, rr.Body.String(), errQuotaExceeded)
+	assert.Equal(t, http.StatusTooManyRequests, send("/").Code)
+}
@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/JMKobayashi/Rate-Limiter-GO/internal/entity"
+)
+
+// TokenRegistry consulta os limites próprios de cada token de API. Retorna
+// nil, sem erro, quando o token não está registrado.
+type TokenRegistry interface {
+	GetTokenPolicy(ctx context.Context, token string) (*entity.TokenPolicy, error)
+}
@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/JMKobayashi/Rate-Limiter-GO/internal/entity"
+	"github.com/JMKobayashi/Rate-Limiter-GO/internal/limiter/strategy"
+)
+
+// DefaultLease é o tempo após o qual uma vaga não liberada expira quando
+// nenhum é configurado
+const DefaultLease = 30 * time.Second
+
+// releaseTimeout limita a liberação de uma vaga ao final da requisição, que
+// não usa o prazo da requisição
+const releaseTimeout = time.Second
+
+// ConcurrencyLimiterUseCaseInterface limita o número de requisições
+// simultâneas por identificador
+type ConcurrencyLimiterUseCaseInterface interface {
+	// Acquire ocupa uma vaga para a requisição. Quando acquired é verdadeiro,
+	// release deve ser chamada ao final da requisição.
+	Acquire(ctx context.Context, identifier string, isToken bool) (release func(), acquired bool, err error)
+}
+
+type ConcurrencyLimiterUseCase struct {
+	repository strategy.ConcurrencyRepository
+	limitIP    int64
+	limitToken int64
+	lease      time.Duration
+	hasher     *entity.TokenHasher
+	now        func() time.Time
+}
+
+// ConcurrencyOption configura parâmetros opcionais do ConcurrencyLimiterUseCase
+type ConcurrencyOption func(*ConcurrencyLimiterUseCase)
+
+// WithConcurrencyTokenHasher grava os tokens no repositório como um
+// HMAC-SHA256, em vez do token em claro
+func WithConcurrencyTokenHasher(hasher *entity.TokenHasher) ConcurrencyOption {
+	return func(uc *ConcurrencyLimiterUseCase) {
+		uc.hasher = hasher
+	}
+}
+
+// NewConcurrencyLimiterUseCase cria o limitador de requisições simultâneas.
+// Um limite zero desabilita a limitação para o tipo de identificador.
+func NewConcurrencyLimiterUseCase(
+	repository strategy.ConcurrencyRepository,
+	limitIP,
+	limitToken int,
+	lease time.Duration,
+	opts ...ConcurrencyOption,
+) ConcurrencyLimiterUseCaseInterface {
+	if lease <= 0 {
+		lease = DefaultLease
+	}
+
+	uc := &ConcurrencyLimiterUseCase{
+		repository: repository,
+		limitIP:    int64(limitIP),
+		limitToken: int64(limitToken),
+		lease:      lease,
+		now:        time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(uc)
+	}
+
+	return uc
+}
+
+func (uc *ConcurrencyLimiterUseCase) Acquire(ctx context.Context, identifier string, isToken bool) (func(), bool, error) {
+	limit := uc.limitIP
+	if isToken {
+		limit = uc.limitToken
+	}
+
+	// Sem limite configurado, a requisição não ocupa vaga
+	if limit <= 0 {
+		return func() {}, true, nil
+	}
+
+	if isToken && uc.hasher != nil {
+		identifier = uc.hasher.Hash(identifier)
+	}
+
+	key := fmt.Sprintf("rate_limiter:%s:%s", map[bool]string{true: "token", false: "ip"}[isToken], identifier)
+
+	result, err := uc.repository.AcquireSlot(ctx, strategy.SlotRequest{
+		Key:   key,
+		Limit: limit,
+		Lease: uc.lease,
+		Now:   uc.now(),
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if !result.Acquired {
+		return nil, false, nil
+	}
+
+	release := func() {
+		// Libera a vaga mesmo que a requisição tenha sido cancelada, sem
+		// esperar um armazenamento indisponível; se falhar, a vaga expira ao
+		// final do lease
+		releaseCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), releaseTimeout)
+		defer cancel()
+		_ = uc.repository.ReleaseSlot(releaseCtx, key, result.SlotID)
+	}
+
+	return release, true, nil
+}
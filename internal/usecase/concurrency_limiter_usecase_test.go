@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/JMKobayashi/Rate-Limiter-GO/internal/limiter/strategy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newConcurrencyRepository(t *testing.T) strategy.ConcurrencyRepository {
+	repo, err := strategy.NewRepository(strategy.MemoryRepository, nil)
+	require.NoError(t, err)
+	return repo.(strategy.ConcurrencyRepository)
+}
+
+func TestConcurrencyLimiterUseCase_Acquire(t *testing.T) {
+	uc := NewConcurrencyLimiterUseCase(newConcurrencyRepository(t), 1, 2, time.Minute)
+	ctx := context.Background()
+
+	// Token com limite de 2 requisições simultâneas
+	release1, acquired, err := uc.Acquire(ctx, "abc123", true)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	release2, acquired, err := uc.Acquire(ctx, "abc123", true)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	_, acquired, err = uc.Acquire(ctx, "abc123", true)
+	require.NoError(t, err)
+	assert.False(t, acquired)
+
+	// O IP tem vagas independentes das do token
+	releaseIP, acquired, err := uc.Acquire(ctx, "abc123", false)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+	releaseIP()
+
+	// Após liberar uma vaga, uma nova requisição é aceita
+	release1()
+	release3, acquired, err := uc.Acquire(ctx, "abc123", true)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	release2()
+	release3()
+}
+
+func TestConcurrencyLimiterUseCase_ReleaseAfterCancel(t *testing.T) {
+	uc := NewConcurrencyLimiterUseCase(newConcurrencyRepository(t), 1, 0, time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	release, acquired, err := uc.Acquire(ctx, "192.168.1.1", false)
+	require.NoError(t, err)
+	require.True(t, acquired)
+
+	// A vaga é liberada mesmo com o contexto da requisição cancelado
+	cancel()
+	release()
+
+	_, acquired, err = uc.Acquire(context.Background(), "192.168.1.1", false)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+}
+
+// blockingReleaseRepository simula um armazenamento que não responde à liberação
+type blockingReleaseRepository struct {
+	strategy.ConcurrencyRepository
+	deadline bool
+}
+
+func (r *blockingReleaseRepository) ReleaseSlot(ctx context.Context, key, slotID string) error {
+	_, r.deadline = ctx.Deadline()
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestConcurrencyLimiterUseCase_ReleaseTimeout(t *testing.T) {
+	repo := &blockingReleaseRepository{ConcurrencyRepository: strategy.NewMemoryRateLimiterRepository().(strategy.ConcurrencyRepository)}
+	uc := NewConcurrencyLimiterUseCase(repo, 1, 0, time.Minute)
+
+	release, acquired, err := uc.Acquire(context.Background(), "192.168.1.1", false)
+	require.NoError(t, err)
+	require.True(t, acquired)
+
+	// A liberação não espera indefinidamente pelo armazenamento
+	start := time.Now()
+	release()
+	assert.True(t, repo.deadline)
+	assert.Less(t, time.Since(start), 2*releaseTimeout)
+}
+
+func TestConcurrencyLimiterUseCase_Disabled(t *testing.T) {
+	uc := NewConcurrencyLimiterUseCase(newConcurrencyRepository(t), 0, 0, 0)
+
+	for i := 0; i < 100; i++ {
+		_, acquired, err := uc.Acquire(context.Background(), "abc123", true)
+		require.NoError(t, err)
+		assert.True(t, acquired)
+	}
+}
@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"context"
+	"time"
+)
+
+// DenyReason indica por que uma requisição foi rejeitada
+type DenyReason string
+
+const (
+	// ReasonRateLimited indica que o limite de curto prazo foi excedido
+	ReasonRateLimited DenyReason = "rate_limited"
+	// ReasonQuotaExceeded indica que uma cota de longo prazo foi esgotada
+	ReasonQuotaExceeded DenyReason = "quota_exceeded"
+)
+
+// Decision é o resultado da verificação de uma requisição
+type Decision struct {
+	Allowed bool
+	// Reason é o motivo da rejeição, vazio quando a requisição é permitida
+	Reason DenyReason
+	// Policy é o nome da política que determinou a decisão (ip, token ou o
+	// nome da cota esgotada)
+	Policy string
+	// Limit é o número de requisições permitidas por janela. Zero indica que
+	// nenhum limite foi aplicado
+	Limit int64
+	// Remaining é a quantidade de requisições ainda disponíveis
+	Remaining int64
+	// ResetAt é o instante em que a cota estará totalmente disponível novamente
+	ResetAt time.Time
+	// RetryAfter é o tempo até a próxima requisição poder ser aceita
+	RetryAfter time.Duration
+	// BlockedUntil é o fim do bloqueio aplicado ao identificador, se houver
+	BlockedUntil time.Time
+	// Policies descreve cada política verificada para a requisição: o limite
+	// de curto prazo e, para tokens, as cotas de longo prazo
+	Policies []PolicyStatus
+}
+
+// PolicyStatus descreve o estado de uma política de limitação após a requisição
+type PolicyStatus struct {
+	// Name identifica a política (ip, token, daily, monthly)
+	Name string
+	// Limit é o número de requisições permitidas em Window
+	Limit int64
+	// Window é a duração da janela ou do período da política
+	Window time.Duration
+	// Remaining é a quantidade de requisições ainda disponíveis
+	Remaining int64
+	// ResetAt é o instante em que a política estará totalmente disponível novamente
+	ResetAt time.Time
+}
+
+// DecisionRateLimiterUseCaseInterface estende RateLimiterUseCaseInterface com
+// a decisão completa. IsAllowed continua disponível como atalho para Allowed.
+type DecisionRateLimiterUseCaseInterface interface {
+	RateLimiterUseCaseInterface
+	Decide(ctx context.Context, identifier string, isToken bool, n int) (*Decision, error)
+}
+
+// Decide obtém a decisão de qualquer caso de uso. Implementações que só
+// oferecem IsAllowed (ou AllowN) resultam em uma decisão sem metadados.
+func Decide(ctx context.Context, uc RateLimiterUseCaseInterface, identifier string, isToken bool, n int) (*Decision, error) {
+	if decider, ok := uc.(DecisionRateLimiterUseCaseInterface); ok {
+		return decider.Decide(ctx, identifier, isToken, n)
+	}
+
+	var allowed bool
+	var err error
+	if weighted, ok := uc.(WeightedRateLimiterUseCaseInterface); ok && n != 1 {
+		allowed, err = weighted.AllowN(ctx, identifier, isToken, n)
+	} else {
+		allowed, err = uc.IsAllowed(ctx, identifier, isToken)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return &Decision{Allowed: false, Reason: ReasonRateLimited}, nil
+	}
+	return &Decision{Allowed: true}, nil
+}
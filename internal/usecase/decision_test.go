@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/JMKobayashi/Rate-Limiter-GO/internal/limiter/strategy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// boolUseCase implementa apenas IsAllowed, como os casos de uso existentes
+type boolUseCase struct {
+	allowed bool
+	err     error
+}
+
+func (m *boolUseCase) IsAllowed(ctx context.Context, identifier string, isToken bool) (bool, error) {
+	return m.allowed, m.err
+}
+
+// weightedUseCase implementa IsAllowed e AllowN, registrando o custo recebido
+type weightedUseCase struct {
+	boolUseCase
+	n int
+}
+
+func (m *weightedUseCase) AllowN(ctx context.Context, identifier string, isToken bool, n int) (bool, error) {
+	m.n = n
+	return m.allowed, m.err
+}
+
+func TestDecide(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Decision from IsAllowed", func(t *testing.T) {
+		decision, err := Decide(ctx, &boolUseCase{allowed: true}, "abc123", true, 1)
+		require.NoError(t, err)
+		assert.Equal(t, &Decision{Allowed: true}, decision)
+
+		decision, err = Decide(ctx, &boolUseCase{allowed: false}, "abc123", true, 1)
+		require.NoError(t, err)
+		assert.Equal(t, &Decision{Allowed: false, Reason: ReasonRateLimited}, decision)
+
+		_, err = Decide(ctx, &boolUseCase{err: errors.New("redis down")}, "abc123", true, 1)
+		assert.Error(t, err)
+	})
+
+	t.Run("Decision from AllowN", func(t *testing.T) {
+		uc := &weightedUseCase{boolUseCase: boolUseCase{allowed: true}}
+		decision, err := Decide(ctx, uc, "abc123", true, 50)
+		require.NoError(t, err)
+		assert.True(t, decision.Allowed)
+		assert.Equal(t, 50, uc.n)
+	})
+
+	t.Run("Full decision", func(t *testing.T) {
+		repo, err := strategy.NewRepository(strategy.MemoryRepository, nil)
+		require.NoError(t, err)
+		uc := NewRateLimiterUseCase(repo, 10, 100, 300, 600, true, true)
+
+		decision, err := Decide(ctx, uc, "192.168.1.1", false, 1)
+		require.NoError(t, err)
+		assert.True(t, decision.Allowed)
+		assert.Equal(t, "ip", decision.Policy)
+		assert.Equal(t, int64(10), decision.Limit)
+		assert.Equal(t, int64(9), decision.Remaining)
+	})
+}
@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/JMKobayashi/Rate-Limiter-GO/internal/limiter/strategy"
+)
+
+// QuotaPeriod define o período de uma cota de longo prazo
+type QuotaPeriod string
+
+const (
+	// DailyQuota é reiniciada à meia-noite
+	DailyQuota QuotaPeriod = "daily"
+	// MonthlyQuota é reiniciada à meia-noite do primeiro dia de cada mês
+	MonthlyQuota QuotaPeriod = "monthly"
+)
+
+// quotaPolicy é uma cota por token com reinício alinhado ao calendário
+type quotaPolicy struct {
+	limit  int64
+	period QuotaPeriod
+}
+
+// WithQuota adiciona uma cota de longo prazo por token, verificada além do
+// limite de curto prazo. Pode ser usada mais de uma vez para combinar cotas
+// diárias e mensais. Um limite zero não adiciona a cota.
+func WithQuota(limit int64, period QuotaPeriod) Option {
+	return func(uc *RateLimiterUseCase) {
+		if limit > 0 {
+			uc.quotas = append(uc.quotas, quotaPolicy{limit: limit, period: period})
+		}
+	}
+}
+
+// WithQuotaLocation define o fuso horário usado para alinhar os períodos das cotas
+func WithQuotaLocation(location *time.Location) Option {
+	return func(uc *RateLimiterUseCase) {
+		if location != nil {
+			uc.quotaLocation = location
+		}
+	}
+}
+
+// periodStart retorna o início do período que contém now no fuso horário informado
+func (p QuotaPeriod) periodStart(now time.Time, location *time.Location) time.Time {
+	now = now.In(location)
+	if p == MonthlyQuota {
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, location)
+	}
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+}
+
+// periodEnd retorna o fim do período que contém now no fuso horário informado
+func (p QuotaPeriod) periodEnd(now time.Time, location *time.Location) time.Time {
+	start := p.periodStart(now, location)
+	if p == MonthlyQuota {
+		return start.AddDate(0, 1, 0)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// quotaRequest monta a requisição de consumo das cotas configuradas
+func (uc *RateLimiterUseCase) quotaRequest(key string, n int, now time.Time) strategy.QuotaRequest {
+	req := strategy.QuotaRequest{
+		Key:    key,
+		Cost:   int64(n),
+		Now:    now,
+		Quotas: make([]strategy.Quota, len(uc.quotas)),
+	}
+	for i, quota := range uc.quotas {
+		req.Quotas[i] = strategy.Quota{
+			Name:    string(quota.period),
+			Limit:   quota.limit,
+			ResetAt: quota.period.periodEnd(now, uc.quotaLocation),
+		}
+	}
+	return req
+}
+
+// quotaStatus descreve o estado das cotas após o consumo
+func (uc *RateLimiterUseCase) quotaStatus(req strategy.QuotaRequest, result *strategy.QuotaResult) []PolicyStatus {
+	statuses := make([]PolicyStatus, len(req.Quotas))
+	for i, quota := range req.Quotas {
+		start := uc.quotas[i].period.periodStart(req.Now, uc.quotaLocation)
+		statuses[i] = PolicyStatus{
+			Name:      quota.Name,
+			Limit:     quota.Limit,
+			Window:    quota.ResetAt.Sub(start),
+			Remaining: max(0, quota.Limit-result.Used[i]),
+			ResetAt:   quota.ResetAt,
+		}
+	}
+	return statuses
+}
@@ -3,24 +3,154 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/JMKobayashi/Rate-Limiter-GO/internal/entity"
 	"github.com/JMKobayashi/Rate-Limiter-GO/internal/limiter/strategy"
+	"github.com/JMKobayashi/Rate-Limiter-GO/internal/repository"
 )
 
+// DefaultWindow é a janela de contagem usada quando nenhuma é configurada
+const DefaultWindow = time.Second
+
+// keySeparator separa o identificador das dimensões adicionais da chave
+const keySeparator = "|"
+
 type RateLimiterUseCaseInterface interface {
 	IsAllowed(ctx context.Context, identifier string, isToken bool) (bool, error)
 }
 
+// WeightedRateLimiterUseCaseInterface é implementado por casos de uso em que
+// uma requisição pode consumir várias unidades da cota de uma vez
+type WeightedRateLimiterUseCaseInterface interface {
+	AllowN(ctx context.Context, identifier string, isToken bool, n int) (bool, error)
+}
+
+// limitPolicy agrupa os parâmetros de limitação de um tipo de identificador
+type limitPolicy struct {
+	limit         int64
+	window        time.Duration
+	burst         int64
+	blockDuration time.Duration
+	algorithm     strategy.Algorithm
+}
+
+// withTokenPolicy aplica os limites próprios do token sobre a política global
+func (p limitPolicy) withTokenPolicy(token *entity.TokenPolicy) limitPolicy {
+	if token == nil {
+		return p
+	}
+	if token.Limit > 0 {
+		p.limit = int64(token.Limit)
+	}
+	if token.BlockDuration > 0 {
+		p.blockDuration = time.Duration(token.BlockDuration) * time.Second
+	}
+	return p
+}
+
 type RateLimiterUseCase struct {
 	repository         strategy.RateLimiterRepository
-	rateLimitIP        int
-	rateLimitToken     int
-	blockDurationIP    int
-	blockDurationToken int
+	namespace          string
+	ipPolicy           limitPolicy
+	tokenPolicy        limitPolicy
+	tokenRegistry      repository.TokenRegistry
+	tokenHasher        *entity.TokenHasher
+	prefixIPv4         int
+	prefixIPv6         int
 	enableIPLimiter    bool
 	enableTokenLimiter bool
+	quotas             []quotaPolicy
+	quotaLocation      *time.Location
+	now                func() time.Time
+	mu                 sync.Mutex
+}
+
+// Option configura parâmetros opcionais do RateLimiterUseCase
+type Option func(*RateLimiterUseCase)
+
+// WithWindows define a duração da janela de contagem para IP e token
+func WithWindows(windowIP, windowToken time.Duration) Option {
+	return func(uc *RateLimiterUseCase) {
+		if windowIP > 0 {
+			uc.ipPolicy.window = windowIP
+		}
+		if windowToken > 0 {
+			uc.tokenPolicy.window = windowToken
+		}
+	}
+}
+
+// WithAlgorithms define o algoritmo de limitação para IP e token
+func WithAlgorithms(algorithmIP, algorithmToken strategy.Algorithm) Option {
+	return func(uc *RateLimiterUseCase) {
+		if algorithmIP != "" {
+			uc.ipPolicy.algorithm = algorithmIP
+		}
+		if algorithmToken != "" {
+			uc.tokenPolicy.algorithm = algorithmToken
+		}
+	}
+}
+
+// WithBurst define a capacidade de rajada para IP e token. Zero mantém o
+// próprio limite como capacidade.
+func WithBurst(burstIP, burstToken int) Option {
+	return func(uc *RateLimiterUseCase) {
+		uc.ipPolicy.burst = int64(burstIP)
+		uc.tokenPolicy.burst = int64(burstToken)
+	}
+}
+
+// WithIPPrefix agrega os IPs pelo tamanho de prefixo da rede, para que um
+// cliente não escape do limite por IP trocando de endereço dentro da própria
+// rede (ex: 64 para IPv6, 24 para IPv4). Zero mantém o IP completo.
+func WithIPPrefix(prefixIPv4, prefixIPv6 int) Option {
+	return func(uc *RateLimiterUseCase) {
+		if prefixIPv4 > 0 {
+			uc.prefixIPv4 = prefixIPv4
+		}
+		if prefixIPv6 > 0 {
+			uc.prefixIPv6 = prefixIPv6
+		}
+	}
+}
+
+// WithKeyNamespace separa as chaves deste caso de uso das demais, para que
+// casos de uso com limites diferentes, como as regras por rota, não
+// compartilhem contadores. As chaves ficam rate_limiter:<namespace>:<ip|token>:<id>.
+// Com a janela fixa, o repositório deve implementar AtomicRateLimiterRepository;
+// caso contrário, as requisições falham com strategy.ErrUnsupportedNamespace.
+func WithKeyNamespace(namespace string) Option {
+	return func(uc *RateLimiterUseCase) {
+		uc.namespace = namespace
+	}
+}
+
+// WithTokenRegistry define o registro consultado para obter os limites
+// próprios de cada token antes de usar os limites globais por token
+func WithTokenRegistry(registry repository.TokenRegistry) Option {
+	return func(uc *RateLimiterUseCase) {
+		uc.tokenRegistry = registry
+	}
+}
+
+// WithTokenHasher grava os tokens no repositório como um HMAC-SHA256, em
+// vez do token em claro, nas chaves e no estado salvo
+func WithTokenHasher(hasher *entity.TokenHasher) Option {
+	return func(uc *RateLimiterUseCase) {
+		uc.tokenHasher = hasher
+	}
+}
+
+// WithClock define a função usada para obter o horário atual
+func WithClock(now func() time.Time) Option {
+	return func(uc *RateLimiterUseCase) {
+		if now != nil {
+			uc.now = now
+		}
+	}
 }
 
 func NewRateLimiterUseCase(
@@ -31,87 +161,314 @@ func NewRateLimiterUseCase(
 	blockDurationToken int,
 	enableIPLimiter,
 	enableTokenLimiter bool,
-) RateLimiterUseCaseInterface {
-	return &RateLimiterUseCase{
-		repository:         repository,
-		rateLimitIP:        rateLimitIP,
-		rateLimitToken:     rateLimitToken,
-		blockDurationIP:    blockDurationIP,
-		blockDurationToken: blockDurationToken,
+	opts ...Option,
+) DecisionRateLimiterUseCaseInterface {
+	uc := &RateLimiterUseCase{
+		repository: repository,
+		ipPolicy: limitPolicy{
+			limit:         int64(rateLimitIP),
+			window:        DefaultWindow,
+			blockDuration: time.Duration(blockDurationIP) * time.Second,
+			algorithm:     strategy.FixedWindow,
+		},
+		tokenPolicy: limitPolicy{
+			limit:         int64(rateLimitToken),
+			window:        DefaultWindow,
+			blockDuration: time.Duration(blockDurationToken) * time.Second,
+			algorithm:     strategy.FixedWindow,
+		},
 		enableIPLimiter:    enableIPLimiter,
 		enableTokenLimiter: enableTokenLimiter,
+		prefixIPv4:         entity.FullPrefixIPv4,
+		prefixIPv6:         entity.FullPrefixIPv6,
+		quotaLocation:      time.UTC,
+		now:                time.Now,
 	}
+
+	for _, opt := range opts {
+		opt(uc)
+	}
+
+	return uc
 }
 
 func (uc *RateLimiterUseCase) IsAllowed(ctx context.Context, identifier string, isToken bool) (bool, error) {
+	return uc.AllowN(ctx, identifier, isToken, 1)
+}
+
+// AllowN verifica se a requisição pode consumir n unidades da cota do
+// identificador. Valores de n menores que 1 consomem uma unidade.
+func (uc *RateLimiterUseCase) AllowN(ctx context.Context, identifier string, isToken bool, n int) (bool, error) {
+	decision, err := uc.Decide(ctx, identifier, isToken, n)
+	if err != nil {
+		return false, err
+	}
+	return decision.Allowed, nil
+}
+
+// Decide verifica se a requisição pode consumir n unidades do limite de curto
+// prazo e, para tokens, das cotas de longo prazo, informando o motivo quando
+// ela é rejeitada
+func (uc *RateLimiterUseCase) Decide(ctx context.Context, identifier string, isToken bool, n int) (*Decision, error) {
+	if n < 1 {
+		n = 1
+	}
+
 	// Se a limitação por token estiver desabilitada e for um token, permite a requisição
 	if isToken && !uc.enableTokenLimiter {
-		return true, nil
+		return &Decision{Allowed: true}, nil
 	}
 
 	// Se a limitação por IP estiver desabilitada e não for um token, permite a requisição
 	if !isToken && !uc.enableIPLimiter {
-		return true, nil
+		return &Decision{Allowed: true}, nil
+	}
+
+	// IPs da mesma rede compartilham o mesmo limite
+	if !isToken {
+		identifier = uc.ipIdentifier(identifier)
+	}
+
+	// Define os limites baseados no tipo
+	policy := uc.ipPolicy
+	if isToken {
+		policy = uc.tokenPolicy
+
+		// Tokens registrados usam seus próprios limites
+		if uc.tokenRegistry != nil {
+			tokenPolicy, err := uc.tokenRegistry.GetTokenPolicy(ctx, identifier)
+			if err != nil {
+				return nil, err
+			}
+			policy = policy.withTokenPolicy(tokenPolicy)
+		}
+
+		// Depois de consultar o registro, o token só é usado na forma de hash
+		if uc.tokenHasher != nil {
+			identifier = uc.tokenHasher.Hash(identifier)
+		}
+	}
+
+	// As cotas pertencem ao token, independente do namespace e das dimensões
+	// adicionais da chave
+	quotaKey := "rate_limiter:token:" + identifier
+
+	// As dimensões adicionais só compõem a chave de armazenamento
+	if suffix := KeySuffix(ctx); suffix != "" {
+		identifier += keySeparator + suffix
 	}
 
 	// Define a chave baseada no tipo (IP ou token)
-	key := fmt.Sprintf("rate_limiter:%s:%s", map[bool]string{true: "token", false: "ip"}[isToken], identifier)
+	kind := map[bool]string{true: "token", false: "ip"}[isToken]
+	key := fmt.Sprintf("rate_limiter:%s:%s", kind, identifier)
+	if uc.namespace != "" {
+		key = fmt.Sprintf("rate_limiter:%s:%s:%s", uc.namespace, kind, identifier)
+	}
+
+	req := strategy.ConsumeRequest{
+		Key:           key,
+		Limit:         policy.limit,
+		Window:        policy.window,
+		Burst:         policy.burst,
+		BlockDuration: policy.blockDuration,
+		Cost:          int64(n),
+		Now:           uc.now(),
+	}
 
-	limiter, err := uc.repository.Get(ctx, key)
+	result, err := uc.consume(ctx, policy.algorithm, req, identifier, isToken)
 	if err != nil {
-		return false, err
+		return nil, err
+	}
+
+	// Nos algoritmos com rajada, as requisições restantes vão até a
+	// capacidade do balde, que é informada como o limite
+	limit := policy.limit
+	burstAlgorithm := policy.algorithm == strategy.TokenBucket || policy.algorithm == strategy.GCRA
+	if burstAlgorithm && policy.burst > 0 {
+		limit = policy.burst
+	}
+
+	decision := &Decision{
+		Allowed:      result.Allowed,
+		Limit:        limit,
+		Remaining:    result.Remaining,
+		Policy:       kind,
+		ResetAt:      result.ResetAt,
+		RetryAfter:   result.RetryAfter,
+		BlockedUntil: result.BlockedUntil,
+		Policies: []PolicyStatus{{
+			Name:      kind,
+			Limit:     limit,
+			Window:    policy.window,
+			Remaining: result.Remaining,
+			ResetAt:   result.ResetAt,
+		}},
+	}
+	if !result.Allowed {
+		decision.Reason = ReasonRateLimited
+		return decision, nil
+	}
+
+	// As cotas de longo prazo só são consumidas por requisições dentro do limite
+	if isToken && len(uc.quotas) > 0 {
+		return uc.consumeQuota(ctx, uc.quotaRequest(quotaKey, n, req.Now), decision)
+	}
+
+	return decision, nil
+}
+
+// ipIdentifier normaliza o IP e o agrega pelo prefixo configurado.
+// Identificadores que não são IPs são mantidos.
+func (uc *RateLimiterUseCase) ipIdentifier(identifier string) string {
+	prefix, err := entity.IPPrefix(identifier, uc.prefixIPv4, uc.prefixIPv6)
+	if err != nil {
+		return identifier
+	}
+	return prefix
+}
+
+// consumeQuota consome as cotas de longo prazo do token. Quando uma cota é
+// esgotada, a decisão passa a descrever a cota em vez do limite de curto prazo.
+func (uc *RateLimiterUseCase) consumeQuota(ctx context.Context, req strategy.QuotaRequest, decision *Decision) (*Decision, error) {
+	repo, ok := uc.repository.(strategy.QuotaRepository)
+	if !ok {
+		return nil, strategy.ErrUnsupportedQuota
+	}
+
+	result, err := repo.ConsumeQuota(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	decision.Policies = append(decision.Policies, uc.quotaStatus(req, result)...)
+
+	if !result.Allowed {
+		for _, quota := range req.Quotas {
+			if quota.Name == result.Exceeded {
+				return &Decision{
+					Allowed:    false,
+					Reason:     ReasonQuotaExceeded,
+					Policy:     quota.Name,
+					Limit:      quota.Limit,
+					ResetAt:    quota.ResetAt,
+					RetryAfter: quota.ResetAt.Sub(req.Now),
+					Policies:   decision.Policies,
+				}, nil
+			}
+		}
+	}
+
+	return decision, nil
+}
+
+// consume aplica o algoritmo configurado usando a operação correspondente do repositório
+func (uc *RateLimiterUseCase) consume(ctx context.Context, algorithm strategy.Algorithm, req strategy.ConsumeRequest, identifier string, isToken bool) (*strategy.ConsumeResult, error) {
+	switch algorithm {
+	case strategy.FixedWindow:
+		// Usa a operação atômica do repositório quando disponível
+		if repo, ok := uc.repository.(strategy.AtomicRateLimiterRepository); ok {
+			return repo.Consume(ctx, req)
+		}
+		// Save grava o rate limiter na chave sem namespace, que nunca seria lida
+		if uc.namespace != "" {
+			return nil, strategy.ErrUnsupportedNamespace
+		}
+		return uc.consumeLimiter(ctx, req, identifier, isToken)
+	case strategy.TokenBucket:
+		if repo, ok := uc.repository.(strategy.TokenBucketRepository); ok {
+			return repo.ConsumeTokenBucket(ctx, req)
+		}
+	case strategy.SlidingWindow:
+		if repo, ok := uc.repository.(strategy.SlidingWindowRepository); ok {
+			return repo.ConsumeSlidingWindow(ctx, req)
+		}
+	case strategy.SlidingLog:
+		if repo, ok := uc.repository.(strategy.SlidingLogRepository); ok {
+			return repo.ConsumeSlidingLog(ctx, req)
+		}
+	case strategy.GCRA:
+		if repo, ok := uc.repository.(strategy.GCRARepository); ok {
+			return repo.ConsumeGCRA(ctx, req)
+		}
 	}
 
+	return nil, fmt.Errorf("%w: %s", strategy.ErrUnsupportedAlgorithm, algorithm)
+}
+
+// consumeLimiter aplica a janela fixa sobre o entity.RateLimiter salvo no
+// repositório, para repositórios sem suporte a operações atômicas
+func (uc *RateLimiterUseCase) consumeLimiter(ctx context.Context, req strategy.ConsumeRequest, identifier string, isToken bool) (*strategy.ConsumeResult, error) {
+	// Serializa o ciclo leitura-alteração-escrita
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	limiter, err := uc.repository.Get(ctx, req.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	now := req.Now
+	resetAt := now.Truncate(req.Window).Add(req.Window)
+
 	// Se não existe um limiter, cria um novo
 	if limiter == nil {
+		limiter = &entity.RateLimiter{
+			Requests:     0,
+			LastRequest:  now,
+			Blocked:      false,
+			BlockedUntil: time.Time{},
+		}
 		if isToken {
-			limiter = &entity.RateLimiter{
-				Token:        identifier,
-				Requests:     0,
-				LastRequest:  time.Now(),
-				Blocked:      false,
-				BlockedUntil: time.Time{},
-			}
+			limiter.Token = identifier
 		} else {
-			limiter, err = entity.NewRateLimiter(identifier, "")
-			if err != nil {
-				return false, err
-			}
+			limiter.IP = identifier
 		}
 	}
 
 	// Verifica se está bloqueado
-	if limiter.IsBlocked() {
-		return false, nil
+	if limiter.IsBlockedAt(now) {
+		return &strategy.ConsumeResult{
+			Allowed:      false,
+			Count:        limiter.Requests,
+			ResetAt:      resetAt,
+			RetryAfter:   limiter.BlockedUntil.Sub(now),
+			BlockedUntil: limiter.BlockedUntil,
+		}, nil
 	}
 
-	// Define os limites baseados no tipo
-	limit := uc.rateLimitIP
-	blockDuration := uc.blockDurationIP
-	if isToken {
-		limit = uc.rateLimitToken
-		blockDuration = uc.blockDurationToken
-	}
+	// Inicia uma nova janela se a última requisição pertence a uma janela anterior
+	limiter.RollWindow(now, req.Window)
 
-	// Incrementa o contador de requisições
-	limiter.Requests++
-	limiter.LastRequest = time.Now()
+	// Incrementa o contador de requisições com o custo da requisição
+	limiter.Requests += req.Cost
+	limiter.LastRequest = now
 
 	// Verifica se excedeu o limite
-	if limiter.Requests > int64(limit) {
-		limiter.Block(time.Duration(blockDuration) * time.Second)
+	if limiter.Requests > req.Limit {
+		limiter.BlockAt(now, req.BlockDuration)
 		err = uc.repository.Save(ctx, limiter)
 		if err != nil {
-			return false, err
+			return nil, err
 		}
-		return false, nil
+		return &strategy.ConsumeResult{
+			Allowed:      false,
+			Count:        limiter.Requests,
+			ResetAt:      resetAt,
+			RetryAfter:   req.BlockDuration,
+			BlockedUntil: limiter.BlockedUntil,
+		}, nil
 	}
 
 	// Salva o estado atual
 	err = uc.repository.Save(ctx, limiter)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
-	return true, nil
+	remaining := req.Limit - limiter.Requests
+	return &strategy.ConsumeResult{
+		Allowed:   true,
+		Count:     limiter.Requests,
+		Remaining: remaining,
+		ResetAt:   resetAt,
+	}, nil
 }
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/JMKobayashi/Rate-Limiter-GO/internal/entity"
+	"github.com/JMKobayashi/Rate-Limiter-GO/internal/limiter/strategy"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -58,9 +59,9 @@ func (m *MockRateLimiterRepository) Save(ctx context.Context, limiter *entity.Ra
 	if m.err != nil {
 		return m.err
 	}
-	key := "rate_limit:" + limiter.IP
+	key := "rate_limiter:ip:" + limiter.IP
 	if limiter.Token != "" {
-		key = "rate_limit:" + limiter.Token
+		key = "rate_limiter:token:" + limiter.Token
 	}
 	m.limiters[key] = limiter
 	return nil
@@ -404,7 +405,11 @@ func TestRateLimiterUseCase_BlockExpiration(t *testing.T) {
 			assert.Equal(t, tt.expectedAfter, allowed)
 
 			// Verificar o estado do limitador
-			got, err := repo.Get(context.Background(), "rate_limit:"+tt.identifier)
+			key := "rate_limiter:ip:" + tt.identifier
+			if tt.isToken {
+				key = "rate_limiter:token:" + tt.identifier
+			}
+			got, err := repo.Get(context.Background(), key)
 			require.NoError(t, err)
 			require.NotNil(t, got)
 
@@ -518,3 +523,722 @@ func TestRateLimiterUseCase_RepositoryErrors(t *testing.T) {
 		assert.Contains(t, err.Error(), "erro simulado ao salvar")
 	})
 }
+
+// fakeClock é um relógio controlado manualmente para testes determinísticos.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// newFakeClock inicia o relógio no início do segundo atual, pois os
+// repositórios expiram bloqueios usando o horário real.
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Now().Truncate(time.Second)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// TestRateLimiterUseCase_Window testa se o contador é reiniciado a cada janela.
+func TestRateLimiterUseCase_Window(t *testing.T) {
+	tests := []struct {
+		name       string
+		identifier string
+		isToken    bool
+		limit      int
+		window     time.Duration
+	}{
+		{
+			name:       "IP dentro do limite em várias janelas",
+			identifier: "192.168.1.1",
+			isToken:    false,
+			limit:      10,
+			window:     time.Second,
+		},
+		{
+			name:       "Token dentro do limite em várias janelas",
+			identifier: "test-token",
+			isToken:    true,
+			limit:      100,
+			window:     time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := newFakeClock()
+			repo := NewMockRateLimiterRepository()
+			useCase := NewRateLimiterUseCase(
+				repo,
+				tt.limit,
+				tt.limit,
+				300,
+				600,
+				true,
+				true,
+				WithWindows(tt.window, tt.window),
+				WithClock(clock.Now),
+			)
+
+			step := tt.window / time.Duration(tt.limit)
+			for w := 0; w < 5; w++ {
+				for i := 0; i < tt.limit; i++ {
+					allowed, err := useCase.IsAllowed(context.Background(), tt.identifier, tt.isToken)
+					require.NoError(t, err)
+					assert.True(t, allowed, "janela %d, requisição %d não deveria ser bloqueada", w, i+1)
+					clock.Advance(step)
+				}
+			}
+		})
+	}
+}
+
+// TestRateLimiterUseCase_WindowExceeded testa o bloqueio dentro de uma única janela.
+func TestRateLimiterUseCase_WindowExceeded(t *testing.T) {
+	clock := newFakeClock()
+	repo := NewMockRateLimiterRepository()
+	useCase := NewRateLimiterUseCase(repo, 3, 100, 5, 600, true, true,
+		WithWindows(time.Second, time.Second),
+		WithClock(clock.Now),
+	)
+
+	for i := 0; i < 3; i++ {
+		allowed, err := useCase.IsAllowed(context.Background(), "192.168.1.1", false)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	}
+
+	// A quarta requisição na mesma janela é bloqueada
+	allowed, err := useCase.IsAllowed(context.Background(), "192.168.1.1", false)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	// Mesmo em uma nova janela, o bloqueio permanece até expirar
+	clock.Advance(2 * time.Second)
+	allowed, err = useCase.IsAllowed(context.Background(), "192.168.1.1", false)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	// Após o bloqueio, uma nova janela é iniciada
+	clock.Advance(5 * time.Second)
+	allowed, err = useCase.IsAllowed(context.Background(), "192.168.1.1", false)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+}
+
+// TestRateLimiterUseCase_AtomicRepository testa o caminho atômico com requisições concorrentes.
+func TestRateLimiterUseCase_AtomicRepository(t *testing.T) {
+	repo, err := strategy.NewRepository(strategy.MemoryRepository, nil)
+	require.NoError(t, err)
+
+	useCase := NewRateLimiterUseCase(repo, 10, 100, 300, 600, true, true,
+		WithWindows(time.Minute, time.Minute),
+	)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	successCount := 0
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			allowed, err := useCase.IsAllowed(context.Background(), "192.168.1.1", false)
+			assert.NoError(t, err)
+			if allowed {
+				mu.Lock()
+				successCount++
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+	assert.Equal(t, 10, successCount)
+
+	// O bloqueio se mantém após exceder o limite
+	allowed, err := useCase.IsAllowed(context.Background(), "192.168.1.1", false)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+}
+
+// TestRateLimiterUseCase_TokenBucket testa a seleção do algoritmo token bucket por tipo de identificador.
+func TestRateLimiterUseCase_TokenBucket(t *testing.T) {
+	clock := newFakeClock()
+	repo, err := strategy.NewRepository(strategy.MemoryRepository, nil)
+	require.NoError(t, err)
+
+	useCase := NewRateLimiterUseCase(repo, 2, 10, 300, 600, true, true,
+		WithAlgorithms(strategy.FixedWindow, strategy.TokenBucket),
+		WithBurst(0, 30),
+		WithClock(clock.Now),
+	)
+
+	// O token aceita uma rajada de até 30 requisições
+	for i := 0; i < 30; i++ {
+		allowed, err := useCase.IsAllowed(context.Background(), "abc123", true)
+		require.NoError(t, err)
+		assert.True(t, allowed, "requisição %d deveria ser permitida", i+1)
+	}
+	allowed, err := useCase.IsAllowed(context.Background(), "abc123", true)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	// Após 1 segundo, 10 novos tokens estão disponíveis
+	clock.Advance(time.Second)
+	for i := 0; i < 10; i++ {
+		allowed, err := useCase.IsAllowed(context.Background(), "abc123", true)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	}
+
+	// O IP continua usando a janela fixa
+	for i := 0; i < 2; i++ {
+		allowed, err := useCase.IsAllowed(context.Background(), "192.168.1.1", false)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	}
+	allowed, err = useCase.IsAllowed(context.Background(), "192.168.1.1", false)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+}
+
+// TestRateLimiterUseCase_UnsupportedAlgorithm testa o erro para repositórios sem suporte ao algoritmo.
+func TestRateLimiterUseCase_UnsupportedAlgorithm(t *testing.T) {
+	repo := NewMockRateLimiterRepository()
+	useCase := NewRateLimiterUseCase(repo, 10, 100, 300, 600, true, true,
+		WithAlgorithms(strategy.TokenBucket, strategy.TokenBucket),
+	)
+
+	allowed, err := useCase.IsAllowed(context.Background(), "192.168.1.1", false)
+	assert.ErrorIs(t, err, strategy.ErrUnsupportedAlgorithm)
+	assert.False(t, allowed)
+}
+
+// TestRateLimiterUseCase_SlidingWindow testa que o sliding window evita rajadas na virada da janela.
+func TestRateLimiterUseCase_SlidingWindow(t *testing.T) {
+	tests := []struct {
+		name      string
+		algorithm strategy.Algorithm
+		expected  int
+	}{
+		{
+			name:      "Janela fixa permite o dobro na virada",
+			algorithm: strategy.FixedWindow,
+			expected:  20,
+		},
+		{
+			name:      "Sliding window respeita o limite na virada",
+			algorithm: strategy.SlidingWindow,
+			expected:  10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := newFakeClock()
+			repo, err := strategy.NewRepository(strategy.MemoryRepository, nil)
+			require.NoError(t, err)
+
+			useCase := NewRateLimiterUseCase(repo, 10, 100, 0, 0, true, true,
+				WithAlgorithms(tt.algorithm, tt.algorithm),
+				WithClock(clock.Now),
+			)
+
+			// 10 requisições no fim de uma janela e 10 no início da seguinte
+			successCount := 0
+			clock.Advance(900 * time.Millisecond)
+			for i := 0; i < 10; i++ {
+				allowed, err := useCase.IsAllowed(context.Background(), "192.168.1.1", false)
+				require.NoError(t, err)
+				if allowed {
+					successCount++
+				}
+			}
+			clock.Advance(100 * time.Millisecond)
+			for i := 0; i < 10; i++ {
+				allowed, err := useCase.IsAllowed(context.Background(), "192.168.1.1", false)
+				require.NoError(t, err)
+				if allowed {
+					successCount++
+				}
+			}
+
+			assert.Equal(t, tt.expected, successCount)
+		})
+	}
+}
+
+// TestRateLimiterUseCase_SlidingLog testa a contagem exata de tentativas com o sliding log.
+func TestRateLimiterUseCase_SlidingLog(t *testing.T) {
+	clock := newFakeClock()
+	repo, err := strategy.NewRepository(strategy.MemoryRepository, nil)
+	require.NoError(t, err)
+
+	useCase := NewRateLimiterUseCase(repo, 5, 100, 0, 0, true, true,
+		WithAlgorithms(strategy.SlidingLog, strategy.FixedWindow),
+		WithWindows(15*time.Minute, time.Second),
+		WithClock(clock.Now),
+	)
+
+	// 5 tentativas espalhadas ao longo de 10 minutos
+	for i := 0; i < 5; i++ {
+		allowed, err := useCase.IsAllowed(context.Background(), "192.168.1.1", false)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+		clock.Advance(2 * time.Minute)
+	}
+
+	// A sexta tentativa é rejeitada até a primeira sair da janela de 15 minutos
+	allowed, err := useCase.IsAllowed(context.Background(), "192.168.1.1", false)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	clock.Advance(5 * time.Minute)
+	allowed, err = useCase.IsAllowed(context.Background(), "192.168.1.1", false)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+}
+
+// TestRateLimiterUseCase_GCRA testa a taxa suave com tolerância de rajada do GCRA.
+func TestRateLimiterUseCase_GCRA(t *testing.T) {
+	clock := newFakeClock()
+	repo, err := strategy.NewRepository(strategy.MemoryRepository, nil)
+	require.NoError(t, err)
+
+	useCase := NewRateLimiterUseCase(repo, 10, 100, 0, 0, true, true,
+		WithAlgorithms(strategy.GCRA, strategy.GCRA),
+		WithBurst(3, 0),
+		WithClock(clock.Now),
+	)
+
+	// Rajada de até 3 requisições
+	for i := 0; i < 3; i++ {
+		allowed, err := useCase.IsAllowed(context.Background(), "192.168.1.1", false)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	}
+	allowed, err := useCase.IsAllowed(context.Background(), "192.168.1.1", false)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	// Depois, uma requisição a cada 100ms
+	for i := 0; i < 10; i++ {
+		clock.Advance(100 * time.Millisecond)
+		allowed, err := useCase.IsAllowed(context.Background(), "192.168.1.1", false)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	}
+}
+
+// TestRateLimiterUseCase_AllowN testa requisições que consomem várias unidades da cota.
+func TestRateLimiterUseCase_AllowN(t *testing.T) {
+	clock := newFakeClock()
+	repo, err := strategy.NewRepository(strategy.MemoryRepository, nil)
+	require.NoError(t, err)
+
+	useCase := NewRateLimiterUseCase(repo, 10, 100, 0, 0, true, true,
+		WithAlgorithms(strategy.FixedWindow, strategy.TokenBucket),
+		WithClock(clock.Now),
+	).(WeightedRateLimiterUseCaseInterface)
+
+	// Uma exportação de custo 50 consome metade da cota do token
+	for i := 0; i < 2; i++ {
+		allowed, err := useCase.AllowN(context.Background(), "abc123", true, 50)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	}
+	allowed, err := useCase.AllowN(context.Background(), "abc123", true, 1)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	// Por IP, 3 requisições de custo 3 cabem no limite de 10, a quarta não
+	for i := 0; i < 3; i++ {
+		allowed, err := useCase.AllowN(context.Background(), "192.168.1.1", false, 3)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	}
+	allowed, err = useCase.AllowN(context.Background(), "192.168.1.1", false, 3)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+}
+
+// TestRateLimiterUseCase_AllowN_Fallback testa o custo no caminho Get/Save.
+func TestRateLimiterUseCase_AllowN_Fallback(t *testing.T) {
+	clock := newFakeClock()
+	repo := NewMockRateLimiterRepository()
+	useCase := NewRateLimiterUseCase(repo, 10, 100, 5, 600, true, true,
+		WithClock(clock.Now),
+	).(WeightedRateLimiterUseCaseInterface)
+
+	allowed, err := useCase.AllowN(context.Background(), "192.168.1.1", false, 6)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, err = useCase.AllowN(context.Background(), "192.168.1.1", false, 6)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+}
+
+// TestRateLimiterUseCase_Quota testa as cotas de longo prazo por token e o motivo da rejeição.
+func TestRateLimiterUseCase_Quota(t *testing.T) {
+	// 23:30 de 14/01 no horário de Brasília (UTC-3)
+	location := time.FixedZone("BRT", -3*60*60)
+	clock := &fakeClock{now: time.Date(2024, 1, 15, 2, 30, 0, 0, time.UTC)}
+	repo, err := strategy.NewRepository(strategy.MemoryRepository, nil)
+	require.NoError(t, err)
+
+	useCase := NewRateLimiterUseCase(repo, 2, 2, 0, 0, true, true,
+		WithQuota(3, DailyQuota),
+		WithQuota(4, MonthlyQuota),
+		WithQuotaLocation(location),
+		WithClock(clock.Now),
+	).(DecisionRateLimiterUseCaseInterface)
+
+	decide := func(identifier string, isToken bool) *Decision {
+		decision, err := useCase.Decide(context.Background(), identifier, isToken, 1)
+		require.NoError(t, err)
+		return decision
+	}
+
+	// O limite de curto prazo é verificado antes das cotas
+	for i := 0; i < 2; i++ {
+		decision := decide("abc123", true)
+		assert.True(t, decision.Allowed)
+		assert.Empty(t, decision.Reason)
+	}
+	decision := decide("abc123", true)
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, ReasonRateLimited, decision.Reason)
+
+	clock.Advance(time.Second)
+	assert.True(t, decide("abc123", true).Allowed)
+
+	decision = decide("abc123", true)
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, ReasonQuotaExceeded, decision.Reason)
+	assert.Equal(t, "daily", decision.Policy)
+
+	// A decisão descreve a cota esgotada, reiniciada à meia-noite no fuso configurado
+	midnight := time.Date(2024, 1, 15, 0, 0, 0, 0, location)
+	assert.Equal(t, int64(3), decision.Limit)
+	assert.Equal(t, int64(0), decision.Remaining)
+	assert.True(t, decision.ResetAt.Equal(midnight))
+	assert.Equal(t, midnight.Sub(clock.Now()), decision.RetryAfter)
+
+	// Cada política verificada é descrita na decisão
+	require.Len(t, decision.Policies, 3)
+	assert.Equal(t, "token", decision.Policies[0].Name)
+	assert.Equal(t, int64(2), decision.Policies[0].Limit)
+	assert.Equal(t, time.Second, decision.Policies[0].Window)
+	assert.Equal(t, int64(0), decision.Policies[0].Remaining)
+	assert.Equal(t, "daily", decision.Policies[1].Name)
+	assert.Equal(t, 24*time.Hour, decision.Policies[1].Window)
+	assert.Equal(t, int64(0), decision.Policies[1].Remaining)
+	assert.Equal(t, "monthly", decision.Policies[2].Name)
+	assert.Equal(t, 31*24*time.Hour, decision.Policies[2].Window)
+	assert.Equal(t, int64(1), decision.Policies[2].Remaining)
+	assert.True(t, decision.Policies[2].ResetAt.Equal(time.Date(2024, 2, 1, 0, 0, 0, 0, location)))
+
+	// As cotas não se aplicam ao IP
+	clock.Advance(time.Second)
+	for i := 0; i < 2; i++ {
+		assert.True(t, decide("192.168.1.1", false).Allowed)
+	}
+
+	// O dia seguinte começa à meia-noite no fuso configurado, e não em UTC
+	clock.Advance(30 * time.Minute)
+	assert.True(t, decide("abc123", true).Allowed)
+
+	decision = decide("abc123", true)
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, ReasonQuotaExceeded, decision.Reason)
+	assert.Equal(t, "monthly", decision.Policy)
+}
+
+// TestRateLimiterUseCase_Decision testa os metadados da decisão usados nos headers de resposta.
+func TestRateLimiterUseCase_Decision(t *testing.T) {
+	clock := newFakeClock()
+	repo, err := strategy.NewRepository(strategy.MemoryRepository, nil)
+	require.NoError(t, err)
+
+	useCase := NewRateLimiterUseCase(repo, 3, 100, 5, 600, true, true,
+		WithClock(clock.Now),
+	).(DecisionRateLimiterUseCaseInterface)
+	resetAt := clock.Now().Add(time.Second)
+
+	for i := 0; i < 3; i++ {
+		decision, err := useCase.Decide(context.Background(), "192.168.1.1", false, 1)
+		require.NoError(t, err)
+		assert.True(t, decision.Allowed)
+		assert.Equal(t, int64(3), decision.Limit)
+		assert.Equal(t, int64(2-i), decision.Remaining)
+		assert.True(t, decision.ResetAt.Equal(resetAt))
+	}
+
+	// Ao exceder o limite o IP é bloqueado por 5 segundos
+	decision, err := useCase.Decide(context.Background(), "192.168.1.1", false, 1)
+	require.NoError(t, err)
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, int64(0), decision.Remaining)
+	assert.Equal(t, 5*time.Second, decision.RetryAfter)
+	assert.True(t, decision.BlockedUntil.Equal(clock.Now().Add(5*time.Second)))
+
+	// Com o limitador desabilitado não há limite a informar
+	useCase = NewRateLimiterUseCase(repo, 3, 100, 5, 600, false, true).(DecisionRateLimiterUseCaseInterface)
+	decision, err = useCase.Decide(context.Background(), "192.168.1.1", false, 1)
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, int64(0), decision.Limit)
+}
+
+// TestRateLimiterUseCase_QuotaUnsupported testa o erro para repositórios sem suporte a cotas.
+func TestRateLimiterUseCase_QuotaUnsupported(t *testing.T) {
+	repo := NewMockRateLimiterRepository()
+	useCase := NewRateLimiterUseCase(repo, 10, 100, 300, 600, true, true,
+		WithQuota(1000, MonthlyQuota),
+	)
+
+	_, err := useCase.IsAllowed(context.Background(), "abc123", true)
+	assert.ErrorIs(t, err, strategy.ErrUnsupportedQuota)
+}
+
+// TestRateLimiterUseCase_TokenRegistry testa os limites próprios de tokens registrados.
+func TestRateLimiterUseCase_TokenRegistry(t *testing.T) {
+	clock := newFakeClock()
+	repo, err := strategy.NewRepository(strategy.MemoryRepository, nil)
+	require.NoError(t, err)
+	registry, err := strategy.NewMemoryTokenRegistry(
+		entity.TokenPolicy{Token: "abc123", Plan: "basic", Limit: 2},
+		entity.TokenPolicy{Token: "premium", Plan: "premium", Limit: 5, BlockDuration: 1},
+	)
+	require.NoError(t, err)
+
+	useCase := NewRateLimiterUseCase(repo, 10, 3, 300, 600, true, true,
+		WithTokenRegistry(registry),
+		WithClock(clock.Now),
+	)
+
+	tests := []struct {
+		token   string
+		allowed int
+	}{
+		{token: "abc123", allowed: 2},
+		{token: "premium", allowed: 5},
+		// Tokens não registrados usam o limite global
+		{token: "unknown", allowed: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.token, func(t *testing.T) {
+			for i := 0; i < tt.allowed; i++ {
+				decision, err := useCase.Decide(context.Background(), tt.token, true, 1)
+				require.NoError(t, err)
+				assert.True(t, decision.Allowed, "requisição %d deveria ser permitida", i+1)
+				assert.Equal(t, int64(tt.allowed), decision.Limit)
+			}
+
+			decision, err := useCase.Decide(context.Background(), tt.token, true, 1)
+			require.NoError(t, err)
+			assert.False(t, decision.Allowed)
+		})
+	}
+
+	// O token premium usa o próprio tempo de bloqueio
+	clock.Advance(2 * time.Second)
+	allowed, err := useCase.IsAllowed(context.Background(), "premium", true)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, err = useCase.IsAllowed(context.Background(), "abc123", true)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+}
+
+func TestRateLimiterUseCase_IPPrefix(t *testing.T) {
+	repo, err := strategy.NewRepository(strategy.MemoryRepository, nil)
+	require.NoError(t, err)
+
+	useCase := NewRateLimiterUseCase(repo, 2, 10, 300, 600, true, true,
+		WithAlgorithms(strategy.SlidingWindow, strategy.SlidingWindow),
+		WithIPPrefix(24, 64),
+	)
+	ctx := context.Background()
+
+	// Endereços diferentes do mesmo /64 compartilham o limite
+	for _, ip := range []string{"2001:db8:1:2::1", "2001:db8:1:2::ffff"} {
+		allowed, err := useCase.IsAllowed(ctx, ip, false)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	}
+	allowed, err := useCase.IsAllowed(ctx, "2001:db8:1:2:abcd::1", false)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	// Outra rede IPv6 tem seu próprio limite
+	allowed, err = useCase.IsAllowed(ctx, "2001:db8:1:3::1", false)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	// IPv6 mapeado para IPv4 usa a mesma chave do IPv4
+	for _, ip := range []string{"203.0.113.1", "::ffff:203.0.113.200"} {
+		allowed, err := useCase.IsAllowed(ctx, ip, false)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	}
+	allowed, err = useCase.IsAllowed(ctx, "203.0.113.99", false)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+}
+
+func TestRateLimiterUseCase_KeyNamespace(t *testing.T) {
+	repo, err := strategy.NewRepository(strategy.MemoryRepository, nil)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	global := NewRateLimiterUseCase(repo, 1, 1, 300, 600, true, true)
+	login := NewRateLimiterUseCase(repo, 1, 1, 300, 600, true, true,
+		WithKeyNamespace("rule:POST:/login"),
+	)
+
+	allowed, err := global.IsAllowed(ctx, "192.168.1.1", false)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	// O namespace separa os contadores dos dois casos de uso
+	allowed, err = login.IsAllowed(ctx, "192.168.1.1", false)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, err = login.IsAllowed(ctx, "192.168.1.1", false)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	// Sem a operação atômica, o estado salvo não seria encontrado no namespace
+	nonAtomic := NewRateLimiterUseCase(NewMockRateLimiterRepository(), 1, 1, 300, 600, true, true,
+		WithKeyNamespace("rule:POST:/login"),
+	)
+	_, err = nonAtomic.IsAllowed(ctx, "192.168.1.1", false)
+	assert.ErrorIs(t, err, strategy.ErrUnsupportedNamespace)
+}
+
+func TestRateLimiterUseCase_KeySuffixNonAtomic(t *testing.T) {
+	repo := NewMockRateLimiterRepository()
+	uc := NewRateLimiterUseCase(repo, 2, 2, 300, 600, true, true)
+	ctx := ContextWithKeySuffix(context.Background(), "GET /search")
+
+	// Sem a operação atômica, o estado com a chave composta é lido de volta
+	for i := 0; i < 2; i++ {
+		allowed, err := uc.IsAllowed(ctx, "abc123", true)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	}
+	allowed, err := uc.IsAllowed(ctx, "abc123", true)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+}
+
+func TestRateLimiterUseCase_TokenHasher(t *testing.T) {
+	repo := NewMockRateLimiterRepository()
+	ctx := context.Background()
+
+	hasher, err := entity.NewTokenHasher([]byte("segredo"))
+	require.NoError(t, err)
+
+	uc := NewRateLimiterUseCase(repo, 1, 1, 300, 600, true, true,
+		WithTokenHasher(hasher),
+	)
+
+	allowed, err := uc.IsAllowed(ctx, "abc123", true)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	// O token só é gravado na forma de hash
+	limiter, err := repo.Get(ctx, "rate_limiter:token:"+hasher.Hash("abc123"))
+	require.NoError(t, err)
+	require.NotNil(t, limiter)
+	assert.Equal(t, hasher.Hash("abc123"), limiter.Token)
+
+	limiter, err = repo.Get(ctx, "rate_limiter:token:abc123")
+	require.NoError(t, err)
+	assert.Nil(t, limiter)
+
+	allowed, err = uc.IsAllowed(ctx, "abc123", true)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+}
+
+func TestRateLimiterUseCase_KeySuffix(t *testing.T) {
+	repo, err := strategy.NewRepository(strategy.MemoryRepository, nil)
+	require.NoError(t, err)
+	registry, err := strategy.NewMemoryTokenRegistry(entity.TokenPolicy{Token: "abc123", Limit: 2})
+	require.NoError(t, err)
+
+	uc := NewRateLimiterUseCase(repo, 1, 10, 300, 600, true, true,
+		WithAlgorithms(strategy.SlidingWindow, strategy.SlidingWindow),
+		WithTokenRegistry(registry),
+		WithIPPrefix(24, 64),
+	)
+	search := ContextWithKeySuffix(context.Background(), "GET /search")
+	users := ContextWithKeySuffix(context.Background(), "GET /users")
+
+	// O limite do registro vale para o token mesmo com a chave composta
+	for i := 0; i < 2; i++ {
+		decision, err := uc.Decide(search, "abc123", true, 1)
+		require.NoError(t, err)
+		assert.True(t, decision.Allowed)
+		assert.Equal(t, int64(2), decision.Limit)
+	}
+	decision, err := uc.Decide(search, "abc123", true, 1)
+	require.NoError(t, err)
+	assert.False(t, decision.Allowed)
+
+	// Cada combinação de dimensões tem seu próprio contador
+	decision, err = uc.Decide(users, "abc123", true, 1)
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed)
+
+	// Endereços do mesmo /64 continuam compartilhando o limite
+	allowed, err := uc.IsAllowed(search, "2001:db8:1:2::1", false)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	allowed, err = uc.IsAllowed(search, "2001:db8:1:2::ffff", false)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+}
+
+func TestRateLimiterUseCase_QuotaKey(t *testing.T) {
+	repo, err := strategy.NewRepository(strategy.MemoryRepository, nil)
+	require.NoError(t, err)
+
+	global := NewRateLimiterUseCase(repo, 10, 10, 0, 0, true, true,
+		WithQuota(3, DailyQuota),
+	)
+	rule := NewRateLimiterUseCase(repo, 10, 10, 0, 0, true, true,
+		WithQuota(3, DailyQuota),
+		WithKeyNamespace("rule:GET:/search"),
+	)
+
+	// As dimensões adicionais e o namespace não separam a cota do token
+	for _, suffix := range []string{"/a", "/b"} {
+		decision, err := global.Decide(ContextWithKeySuffix(context.Background(), suffix), "abc123", true, 1)
+		require.NoError(t, err)
+		assert.True(t, decision.Allowed)
+	}
+	decision, err := rule.Decide(context.Background(), "abc123", true, 1)
+	require.NoError(t, err)
+	assert.True(t, decision.Allowed)
+
+	decision, err = global.Decide(ContextWithKeySuffix(context.Background(), "/c"), "abc123", true, 1)
+	require.NoError(t, err)
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, ReasonQuotaExceeded, decision.Reason)
+}
@@ -0,0 +1,52 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/JMKobayashi/Rate-Limiter-GO/internal/entity"
+	"github.com/JMKobayashi/Rate-Limiter-GO/internal/repository"
+)
+
+// tokenPolicyKey é a chave da política de token no contexto da requisição
+type tokenPolicyKey struct{}
+
+// ContextWithTokenPolicy anexa ao contexto os limites próprios do token da
+// requisição, como os informados nos claims de um JWT
+func ContextWithTokenPolicy(ctx context.Context, policy *entity.TokenPolicy) context.Context {
+	return context.WithValue(ctx, tokenPolicyKey{}, policy)
+}
+
+// keySuffixKey é a chave das dimensões adicionais da chave no contexto
+type keySuffixKey struct{}
+
+// ContextWithKeySuffix anexa ao contexto as dimensões adicionadas ao
+// identificador na chave de armazenamento, como a rota ou um header. O
+// identificador continua sendo usado sozinho para consultar o registro de
+// tokens e agrupar os IPs por rede.
+func ContextWithKeySuffix(ctx context.Context, suffix string) context.Context {
+	return context.WithValue(ctx, keySuffixKey{}, suffix)
+}
+
+// KeySuffix retorna as dimensões da chave anexadas com ContextWithKeySuffix
+func KeySuffix(ctx context.Context) string {
+	suffix, _ := ctx.Value(keySuffixKey{}).(string)
+	return suffix
+}
+
+// contextTokenRegistry é um registro de tokens que lê a política do contexto
+type contextTokenRegistry struct{}
+
+// ContextTokenRegistry cria um registro de tokens que retorna a política
+// anexada ao contexto com ContextWithTokenPolicy, quando ela é do token consultado
+func ContextTokenRegistry() repository.TokenRegistry {
+	return contextTokenRegistry{}
+}
+
+// GetTokenPolicy retorna a política do contexto se ela pertence ao token
+func (contextTokenRegistry) GetTokenPolicy(ctx context.Context, token string) (*entity.TokenPolicy, error) {
+	policy, ok := ctx.Value(tokenPolicyKey{}).(*entity.TokenPolicy)
+	if !ok || policy == nil || policy.Token != token {
+		return nil, nil
+	}
+	return policy, nil
+}
@@ -0,0 +1,29 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/JMKobayashi/Rate-Limiter-GO/internal/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextTokenRegistry(t *testing.T) {
+	registry := ContextTokenRegistry()
+	policy := &entity.TokenPolicy{Token: "acme", Limit: 500}
+	ctx := ContextWithTokenPolicy(context.Background(), policy)
+
+	found, err := registry.GetTokenPolicy(ctx, "acme")
+	require.NoError(t, err)
+	assert.Equal(t, policy, found)
+
+	// A política do contexto só vale para o próprio token
+	found, err = registry.GetTokenPolicy(ctx, "other")
+	require.NoError(t, err)
+	assert.Nil(t, found)
+
+	found, err = registry.GetTokenPolicy(context.Background(), "acme")
+	require.NoError(t, err)
+	assert.Nil(t, found)
+}
@@ -9,16 +9,63 @@ import (
 )
 
 type Config struct {
-	RedisHost          string
-	RedisPort          string
-	RedisPassword      string
-	RedisDB            int
-	RateLimitIP        int
-	RateLimitToken     int
-	BlockDurationIP    int
-	BlockDurationToken int
-	EnableIPLimiter    bool
-	EnableTokenLimiter bool
+	RedisHost           string
+	RedisPort           string
+	RedisPassword       string
+	RedisDB             int
+	RateLimitIP         int
+	RateLimitToken      int
+	BlockDurationIP     int
+	BlockDurationToken  int
+	EnableIPLimiter     bool
+	EnableTokenLimiter  bool
+	WindowIP            int
+	WindowToken         int
+	AlgorithmIP         string
+	AlgorithmToken      string
+	BurstIP             int
+	BurstToken          int
+	LeakyBucketEnabled  bool
+	LeakyBucketRate     int
+	LeakyBucketQueue    int
+	LeakyBucketMaxWait  int
+	ConcurrencyIP       int
+	ConcurrencyToken    int
+	ConcurrencyLease    int
+	RouteCosts          string
+	RouteRules          string
+	KeyDimensions       string
+	QuotaDailyToken     int
+	QuotaMonthlyToken   int
+	QuotaTimezone       string
+	HeaderStyle         string
+	TokenRegistryFile   string
+	TokenRegistryRedis  bool
+	UnknownKeyPolicy    string
+	TokenSources        string
+	JWTSecretFile       string
+	JWTPublicKeyFile    string
+	JWTJWKSFile         string
+	JWTIdentityClaim    string
+	JWTLimitClaim       string
+	TokenHashSecretFile string
+	TokenHashMigrate    bool
+	TrustedProxies      []string
+	ClientIPHeaders     []string
+	IPPrefixV4          int
+	IPPrefixV6          int
+	IPAllowlist         []string
+	IPDenylist          []string
+	IPAccessListFile    string
+	FailurePolicy       string
+	FailureRetryAfter   int
+	StorageTimeout      int
+	BreakerEnabled      bool
+	BreakerFailureRate  int
+	BreakerMinRequests  int
+	BreakerWindow       int
+	BreakerOpenTimeout  int
+	BreakerProbes       int
 }
 
 func LoadConfig() (*Config, error) {
@@ -28,16 +75,63 @@ func LoadConfig() (*Config, error) {
 	}
 
 	config := &Config{
-		RedisHost:          getEnv("REDIS_HOST", "localhost"),
-		RedisPort:          getEnv("REDIS_PORT", "6379"),
-		RedisPassword:      getEnv("REDIS_PASSWORD", ""),
-		RedisDB:            getEnvAsInt("REDIS_DB", 0),
-		RateLimitIP:        getEnvAsInt("RATE_LIMIT_IP", 10),
-		RateLimitToken:     getEnvAsInt("RATE_LIMIT_TOKEN", 100),
-		BlockDurationIP:    getEnvAsInt("BLOCK_DURATION_IP", 300),
-		BlockDurationToken: getEnvAsInt("BLOCK_DURATION_TOKEN", 600),
-		EnableIPLimiter:    getEnvAsBool("ENABLE_IP_LIMITER", true),
-		EnableTokenLimiter: getEnvAsBool("ENABLE_TOKEN_LIMITER", true),
+		RedisHost:           getEnv("REDIS_HOST", "localhost"),
+		RedisPort:           getEnv("REDIS_PORT", "6379"),
+		RedisPassword:       getEnv("REDIS_PASSWORD", ""),
+		RedisDB:             getEnvAsInt("REDIS_DB", 0),
+		RateLimitIP:         getEnvAsInt("RATE_LIMIT_IP", 10),
+		RateLimitToken:      getEnvAsInt("RATE_LIMIT_TOKEN", 100),
+		BlockDurationIP:     getEnvAsInt("BLOCK_DURATION_IP", 300),
+		BlockDurationToken:  getEnvAsInt("BLOCK_DURATION_TOKEN", 600),
+		EnableIPLimiter:     getEnvAsBool("ENABLE_IP_LIMITER", true),
+		EnableTokenLimiter:  getEnvAsBool("ENABLE_TOKEN_LIMITER", true),
+		WindowIP:            getEnvAsInt("RATE_LIMIT_WINDOW_IP", 1),
+		WindowToken:         getEnvAsInt("RATE_LIMIT_WINDOW_TOKEN", 1),
+		AlgorithmIP:         getEnv("RATE_LIMIT_ALGORITHM_IP", "fixed_window"),
+		AlgorithmToken:      getEnv("RATE_LIMIT_ALGORITHM_TOKEN", "fixed_window"),
+		BurstIP:             getEnvAsInt("RATE_LIMIT_BURST_IP", 0),
+		BurstToken:          getEnvAsInt("RATE_LIMIT_BURST_TOKEN", 0),
+		LeakyBucketEnabled:  getEnvAsBool("LEAKY_BUCKET_ENABLED", false),
+		LeakyBucketRate:     getEnvAsInt("LEAKY_BUCKET_RATE", 10),
+		LeakyBucketQueue:    getEnvAsInt("LEAKY_BUCKET_MAX_QUEUE", 10),
+		LeakyBucketMaxWait:  getEnvAsInt("LEAKY_BUCKET_MAX_WAIT_MS", 1000),
+		ConcurrencyIP:       getEnvAsInt("CONCURRENCY_LIMIT_IP", 0),
+		ConcurrencyToken:    getEnvAsInt("CONCURRENCY_LIMIT_TOKEN", 0),
+		ConcurrencyLease:    getEnvAsInt("CONCURRENCY_LEASE", 30),
+		RouteCosts:          getEnv("RATE_LIMIT_ROUTE_COSTS", ""),
+		RouteRules:          getEnv("RATE_LIMIT_RULES", ""),
+		KeyDimensions:       getEnv("RATE_LIMIT_KEY", ""),
+		QuotaDailyToken:     getEnvAsInt("QUOTA_DAILY_TOKEN", 0),
+		QuotaMonthlyToken:   getEnvAsInt("QUOTA_MONTHLY_TOKEN", 0),
+		QuotaTimezone:       getEnv("QUOTA_TIMEZONE", "UTC"),
+		HeaderStyle:         getEnv("RATE_LIMIT_HEADERS", "legacy"),
+		TokenRegistryFile:   getEnv("TOKEN_REGISTRY_FILE", ""),
+		TokenRegistryRedis:  getEnvAsBool("TOKEN_REGISTRY_REDIS", false),
+		UnknownKeyPolicy:    getEnv("UNKNOWN_KEY_POLICY", "allow"),
+		TokenSources:        getEnv("TOKEN_SOURCES", "header:API_KEY"),
+		JWTSecretFile:       getEnv("JWT_HS256_SECRET_FILE", ""),
+		JWTPublicKeyFile:    getEnv("JWT_RS256_PUBLIC_KEY_FILE", ""),
+		JWTJWKSFile:         getEnv("JWT_JWKS_FILE", ""),
+		JWTIdentityClaim:    getEnv("JWT_IDENTITY_CLAIM", "sub"),
+		JWTLimitClaim:       getEnv("JWT_LIMIT_CLAIM", ""),
+		TokenHashSecretFile: getEnv("TOKEN_HASH_SECRET_FILE", ""),
+		TokenHashMigrate:    getEnvAsBool("TOKEN_HASH_MIGRATE", false),
+		TrustedProxies:      getEnvAsList("TRUSTED_PROXIES"),
+		ClientIPHeaders:     getEnvAsList("CLIENT_IP_HEADERS"),
+		IPPrefixV4:          getEnvAsInt("RATE_LIMIT_IPV4_PREFIX", 32),
+		IPPrefixV6:          getEnvAsInt("RATE_LIMIT_IPV6_PREFIX", 64),
+		IPAllowlist:         getEnvAsList("IP_ALLOWLIST"),
+		IPDenylist:          getEnvAsList("IP_DENYLIST"),
+		IPAccessListFile:    getEnv("IP_ACCESS_LIST_FILE", ""),
+		FailurePolicy:       getEnv("FAILURE_POLICY", "closed"),
+		FailureRetryAfter:   getEnvAsInt("FAILURE_RETRY_AFTER", 5),
+		StorageTimeout:      getEnvAsInt("STORAGE_TIMEOUT_MS", 100),
+		BreakerEnabled:      getEnvAsBool("CIRCUIT_BREAKER_ENABLED", true),
+		BreakerFailureRate:  getEnvAsInt("CIRCUIT_BREAKER_FAILURE_RATE", 50),
+		BreakerMinRequests:  getEnvAsInt("CIRCUIT_BREAKER_MIN_REQUESTS", 20),
+		BreakerWindow:       getEnvAsInt("CIRCUIT_BREAKER_WINDOW", 10),
+		BreakerOpenTimeout:  getEnvAsInt("CIRCUIT_BREAKER_OPEN_TIMEOUT", 5),
+		BreakerProbes:       getEnvAsInt("CIRCUIT_BREAKER_PROBES", 3),
 	}
 
 	return config, nil
@@ -73,3 +167,19 @@ func getEnvAsBool(key string, defaultValue bool) bool {
 
 	return strings.ToLower(value) == "true"
 }
+
+func getEnvAsList(key string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}